@@ -10,13 +10,12 @@ import (
 	"github.com/sanLimbu/todo-api/pkg/openapi3"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 func main() {
@@ -66,15 +65,15 @@ func main() {
 
 }
 
-//initTracer initializes OpenTelemetry tracing with Jaeger and stdout exporters
+//initTracer initializes OpenTelemetry tracing with OTLP and stdout exporters
 func initTracer() {
 
-	jaegerEndpoint := "http://localhost:14268/api/traces"
+	otlpEndpoint := "http://localhost:4317"
 
-	//Create a Jaeger exporter
-	jaegerExporter, err := jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(jaegerEndpoint)))
+	//Create an OTLP exporter
+	otlpExporter, err := otlptracegrpc.New(context.Background(), otlptracegrpc.WithEndpointURL(otlpEndpoint))
 	if err != nil {
-		log.Fatalf("Couldn't initialize stdout exporter: ", err)
+		log.Fatalf("Couldn't initialize OTLP exporter: %s", err)
 	}
 
 	//Create a stdout exporter to print traces to the console
@@ -86,11 +85,11 @@ func initTracer() {
 	//Create a trace provider with the exporters
 	tp := trace.NewTracerProvider(
 		trace.WithSampler(trace.AlwaysSample()),
-		trace.WithBatcher(jaegerExporter),
-		trace.WithResource(resource.NewSchemaless(attribute.KeyValue{
-			Key:   semconv.ServiceNameKey,
-			Value: attribute.StringValue("rest-server"),
-		})),
+		trace.WithBatcher(otlpExporter),
+		trace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName("cli"),
+			semconv.ServiceNamespace("todo-api"),
+		)),
 	)
 
 	//Set the global trace provider and propagator
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/cmd/internal"
+	internaldomain "github.com/sanLimbu/todo-api/internal"
+	envvar "github.com/sanLimbu/todo-api/internal/envar"
+	"github.com/sanLimbu/todo-api/internal/event"
+	"github.com/sanLimbu/todo-api/internal/kafka"
+	"github.com/sanLimbu/todo-api/internal/rabbitmq"
+	"github.com/sanLimbu/todo-api/internal/redis"
+)
+
+const (
+	sourceKafka    = "kafka"
+	sourceRabbitMQ = "rabbitmq"
+	sourceRedis    = "redis"
+
+	// kafkaIdle is how long to wait for new dead letters before assuming there are no more.
+	kafkaIdle = 10 * time.Second
+)
+
+// deadLetter represents the dead letter destination of a message broker.
+type deadLetter interface {
+	List(ctx context.Context, limit int) ([]event.DeadLetter, error)
+	Replay(ctx context.Context, limit int) (int, error)
+}
+
+func main() {
+	var env, source string
+	var replay bool
+	var limit int
+
+	flag.StringVar(&env, "env", "", "Environment Variables filename")
+	flag.StringVar(&source, "source", sourceRedis, "Message broker holding dead letters: kafka, rabbitmq or redis")
+	flag.BoolVar(&replay, "replay", false, "Publish dead letters back to their original destination")
+	flag.IntVar(&limit, "limit", 100, "Maximum number of dead letters to list or replay")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(),
+		os.Interrupt,
+		syscall.SIGTERM,
+		syscall.SIGQUIT)
+	defer stop()
+
+	if err := run(ctx, env, source, replay, limit); err != nil {
+		log.Fatalf("Couldn't run: %s", err)
+	}
+}
+
+func run(ctx context.Context, env, source string, replay bool, limit int) error {
+	if err := envvar.Load(env); err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "envvar.Load")
+	}
+
+	secrets, err := internal.NewSecretsProvider(zap.NewNop())
+	if err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewSecretsProvider")
+	}
+	defer secrets.Close()
+
+	conf := envvar.New(secrets)
+
+	dl, closeFn, err := newDeadLetter(conf, source)
+	if err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "newDeadLetter")
+	}
+	defer closeFn()
+
+	if replay {
+		n, err := dl.Replay(ctx, limit)
+		if err != nil {
+			return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "Replay, %d replayed", n)
+		}
+
+		fmt.Printf("Replayed %d dead letters\n", n)
+
+		return nil
+	}
+
+	dls, err := dl.List(ctx, limit)
+	if err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "List")
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+
+	for _, d := range dls {
+		if err := enc.Encode(newOutput(d)); err != nil {
+			return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "enc.Encode")
+		}
+	}
+
+	return nil
+}
+
+func newDeadLetter(conf *envvar.Configuration, source string) (deadLetter, func(), error) {
+	switch source {
+	case sourceKafka:
+		producer, err := internal.NewKafkaProducer(conf)
+		if err != nil {
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewKafkaProducer")
+		}
+
+		consumer, err := internal.NewKafkaDeadLetterConsumer(conf, "dead-letter")
+		if err != nil {
+			_ = producer.Close()
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewKafkaDeadLetterConsumer")
+		}
+
+		closeFn := func() {
+			consumer.Consumer.Close()
+			_ = producer.Close()
+		}
+
+		return &kafkaDeadLetter{
+			dl: kafka.NewDeadLetter(producer.Producer, consumer.Consumer, producer.DeadLetterTopic),
+		}, closeFn, nil
+	case sourceRabbitMQ:
+		rmq, err := internal.NewRabbitMQ(conf, zap.NewNop())
+		if err != nil {
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewRabbitMQ")
+		}
+
+		return rabbitmq.NewDeadLetter(rmq), rmq.Close, nil
+	case sourceRedis:
+		rdb, err := internal.NewRedis(conf)
+		if err != nil {
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewRedis")
+		}
+
+		return redis.NewDeadLetter(rdb), func() { _ = rdb.Close() }, nil
+	}
+
+	return nil, nil, internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "unknown source %q", source)
+}
+
+// kafkaDeadLetter stops reading the dead letter topic after no messages are received for a while, because topics,
+// unlike queues and streams, don't indicate when they are empty.
+type kafkaDeadLetter struct {
+	dl *kafka.DeadLetter
+}
+
+func (k *kafkaDeadLetter) List(ctx context.Context, limit int) ([]event.DeadLetter, error) {
+	return k.dl.List(ctx, limit, kafkaIdle)
+}
+
+func (k *kafkaDeadLetter) Replay(ctx context.Context, limit int) (int, error) {
+	return k.dl.Replay(ctx, limit, kafkaIdle)
+}
+
+type output struct {
+	ID          string          `json:"id"`
+	Destination string          `json:"destination"`
+	Error       string          `json:"error,omitempty"`
+	FailedAt    time.Time       `json:"failed_at"`
+	ContentType string          `json:"content_type,omitempty"`
+	Event       json.RawMessage `json:"event,omitempty"`
+	Body        []byte          `json:"body,omitempty"`
+}
+
+// newOutput includes the body as JSON when it is valid, otherwise it is base64 encoded, for example Protobuf events.
+func newOutput(d event.DeadLetter) output {
+	res := output{
+		ID:          d.ID,
+		Destination: d.Destination,
+		Error:       d.Error,
+		FailedAt:    d.FailedAt,
+		ContentType: d.ContentType,
+	}
+
+	if json.Valid(d.Body) {
+		res.Event = d.Body
+	} else {
+		res.Body = d.Body
+	}
+
+	return res
+}
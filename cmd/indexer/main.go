@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	esv7 "github.com/elastic/go-elasticsearch/v7"
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/cmd/internal"
+	internaldomain "github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/cache"
+	"github.com/sanLimbu/todo-api/internal/elasticsearch"
+	envvar "github.com/sanLimbu/todo-api/internal/envar"
+	"github.com/sanLimbu/todo-api/internal/health"
+	"github.com/sanLimbu/todo-api/internal/indexer"
+	"github.com/sanLimbu/todo-api/internal/kafka"
+	"github.com/sanLimbu/todo-api/internal/rabbitmq"
+	"github.com/sanLimbu/todo-api/internal/redis"
+	"github.com/sanLimbu/todo-api/internal/retry"
+)
+
+const (
+	sourceKafka    = "kafka"
+	sourceRabbitMQ = "rabbitmq"
+	sourceRedis    = "redis"
+
+	// consumerName identifies the indexer in all message brokers: Kafka consumer group, RabbitMQ queue and Redis consumer group.
+	consumerName = "elasticsearch-indexer"
+)
+
+func main() {
+	var env, source, address string
+	var printCfg bool
+
+	flag.StringVar(&env, "env", "", "Environment Variables filename")
+	flag.StringVar(&source, "source", sourceRedis, "Message broker to consume events from: kafka, rabbitmq or redis")
+	flag.StringVar(&address, "address", ":9235", "HTTP Server Address serving metrics and health checks")
+	flag.BoolVar(&printCfg, "print-config", false, "Prints the effective configuration with secrets redacted and exits")
+	flag.Parse()
+
+	if printCfg {
+		if err := printConfig(env, source); err != nil {
+			log.Fatalf("Couldn't print configuration: %s", err)
+		}
+
+		return
+	}
+
+	errC, err := run(env, source, address)
+	if err != nil {
+		log.Fatalf("Couldn't run: %s", err)
+	}
+
+	if err := <-errC; err != nil {
+		log.Fatalf("Error while running: %s", err)
+	}
+}
+
+func run(env, source, address string) (<-chan error, error) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "zap.NewProduction")
+	}
+
+	logger = logger.With(zap.String("source", source))
+
+	conf, closeConf, err := newConfiguration(env, logger)
+	if err != nil {
+		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "newConfiguration")
+	}
+
+	esClient, err := internal.NewElasticSearch(conf)
+	if err != nil {
+		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewElasticSearch")
+	}
+
+	telemetry, err := internal.NewTelemetry(conf, "elasticsearch-indexer-"+source)
+	if err != nil {
+		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewTelemetry")
+	}
+
+	var healthCfg internal.HealthConfig
+
+	if err := conf.Load(&healthCfg); err != nil {
+		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeInvalidArgument, "conf.Load")
+	}
+
+	checker := health.NewChecker(healthCfg.Timeout, healthCfg.CacheTTL)
+	checker.Add("elasticsearch", internal.ElasticsearchCheck(esClient))
+
+	task, closeCache, err := newTaskIndexer(conf, esClient, checker, logger)
+	if err != nil {
+		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "newTaskIndexer")
+	}
+
+	consumer, closeFn, err := newConsumer(conf, source, checker, logger)
+	if err != nil {
+		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "newConsumer")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", telemetry.Metrics)
+	checker.Register(mux)
+
+	srv := &Server{
+		logger:   logger,
+		health:   checker,
+		consumer: consumer,
+		handler:  indexer.NewHandler(task, retry.NewPolicy(), source),
+		metrics: &http.Server{
+			Handler:           mux,
+			Addr:              address,
+			ReadTimeout:       1 * time.Second,
+			ReadHeaderTimeout: 1 * time.Second,
+			WriteTimeout:      1 * time.Second,
+			IdleTimeout:       1 * time.Second,
+		},
+		doneC:  make(chan struct{}),
+		closeC: make(chan struct{}),
+	}
+
+	errC := make(chan error, 1)
+
+	ctx, stop := signal.NotifyContext(context.Background(),
+		os.Interrupt,
+		syscall.SIGTERM,
+		syscall.SIGQUIT)
+
+	go func() {
+		<-ctx.Done()
+		logger.Info("Shutdown signal received")
+
+		ctxTimeout, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+
+		defer func() {
+			_ = logger.Sync()
+			closeFn()
+			closeCache()
+			closeConf()
+			stop()
+			cancel()
+			close(errC)
+		}()
+
+		if err := srv.Shutdown(ctxTimeout); err != nil {
+			errC <- err
+		}
+
+		if err := telemetry.Shutdown(ctxTimeout); err != nil {
+			logger.Error("Couldn't export telemetry", zap.Error(err))
+		}
+
+		logger.Info("Shutdown completed")
+	}()
+
+	go func() {
+		logger.Info("Listening and serving", zap.String("address", address))
+
+		if err := srv.ListenAndServe(); err != nil {
+			errC <- err
+		}
+	}()
+
+	return errC, nil
+}
+
+// newConfiguration loads the env file, the returned function closes the secrets providers.
+func newConfiguration(env string, logger *zap.Logger) (*envvar.Configuration, func(), error) {
+	if err := envvar.Load(env); err != nil {
+		return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "envvar.Load")
+	}
+
+	secrets, err := internal.NewSecretsProvider(logger)
+	if err != nil {
+		return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewSecretsProvider")
+	}
+
+	return envvar.New(secrets), secrets.Close, nil
+}
+
+// printConfig prints the settings of the services used when consuming events from source.
+func printConfig(env, source string) error {
+	conf, closeConf, err := newConfiguration(env, zap.NewNop())
+	if err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "newConfiguration")
+	}
+	defer closeConf()
+
+	sections := []interface{}{&internal.SecretsConfig{}, &internal.TelemetryConfig{}, &internal.HealthConfig{}, &internal.ElasticsearchConfig{}}
+
+	usesRedis := false
+
+	switch source {
+	case sourceKafka:
+		sections = append(sections, &internal.KafkaConfig{})
+	case sourceRabbitMQ:
+		sections = append(sections, &internal.RabbitMQConfig{})
+	case sourceRedis:
+		usesRedis = true
+	default:
+		return internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "unknown source %q", source)
+	}
+
+	// Invalid values are reported when printing, here they only select the sections to print.
+	if ok, err := usesCache(conf); ok || err != nil {
+		var cacheCfg internal.CacheConfig
+
+		_ = conf.Load(&cacheCfg)
+
+		sections = append(sections, &cacheCfg)
+
+		switch cacheCfg.Backend {
+		case internal.CacheBackendMemcached:
+			sections = append(sections, &internal.MemcachedConfig{})
+		case internal.CacheBackendRedis:
+			usesRedis = true
+		}
+	}
+
+	if usesRedis {
+		sections = append(sections, &internal.RedisConfig{})
+	}
+
+	return internal.PrintConfig(os.Stdout, conf, sections...)
+}
+
+// newTaskIndexer returns the Elasticsearch indexer, when a shared cache is configured the search results cached by the
+// rest-server are invalidated after every indexed task and the cache is checked for readiness.
+func newTaskIndexer(conf *envvar.Configuration, esClient *esv7.Client, checker *health.Checker, logger *zap.Logger) (indexer.TaskIndexer, func(), error) {
+	task := elasticsearch.NewTask(esClient)
+
+	ok, err := usesCache(conf)
+	if err != nil {
+		return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "usesCache")
+	}
+
+	if !ok {
+		return task, func() {}, nil
+	}
+
+	c, closeFn, err := internal.NewCache(conf)
+	if err != nil {
+		return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewCache")
+	}
+
+	checker.Add("cache", internal.CacheCheck(c))
+
+	return cache.NewSearchableTask(c, task, logger), closeFn, nil
+}
+
+// usesCache indicates whether the cache shared with the rest-server is configured, results cached in memory by the
+// rest-server process can't be invalidated from here and memcached is optional.
+func usesCache(conf *envvar.Configuration) (bool, error) {
+	var cfg internal.CacheConfig
+
+	if err := conf.Load(&cfg); err != nil {
+		return false, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeInvalidArgument, "conf.Load")
+	}
+
+	switch cfg.Backend {
+	case internal.CacheBackendMemory:
+		return false, nil
+	case internal.CacheBackendMemcached:
+		host, err := conf.Get("MEMCACHED_HOST")
+		if err != nil {
+			return false, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "conf.Get MEMCACHED_HOST")
+		}
+
+		return host != "", nil
+	}
+
+	return true, nil
+}
+
+// newConsumer instantiates the consumer of source, the returned function releases its resources. Redis is checked
+// for readiness, the Kafka and RabbitMQ clients reconnect on their own.
+func newConsumer(conf *envvar.Configuration, source string, checker *health.Checker, logger *zap.Logger) (indexer.Consumer, func(), error) {
+	switch source {
+	case sourceKafka:
+		consumer, err := internal.NewKafkaConsumer(conf, consumerName)
+		if err != nil {
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewKafkaConsumer")
+		}
+
+		// The producer is used for dead lettering messages.
+		producer, err := internal.NewKafkaProducer(conf)
+		if err != nil {
+			_ = consumer.Consumer.Close()
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewKafkaProducer")
+		}
+
+		closeFn := func() {
+			_ = consumer.Consumer.Close()
+			_ = producer.Close()
+		}
+
+		// Producer errors are only reported using its events channel, it must be drained so it never fills up.
+		go kafka.ReportDeliveries(producer.Producer, logger)
+
+		deadLetter := kafka.NewDeadLetter(producer.Producer, nil, producer.DeadLetterTopic)
+
+		return kafka.NewConsumer(consumer.Consumer, deadLetter, consumer.Workers, logger), closeFn, nil
+	case sourceRabbitMQ:
+		rmq, err := internal.NewRabbitMQ(conf, logger)
+		if err != nil {
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewRabbitMQ")
+		}
+
+		hostname, err := os.Hostname()
+		if err != nil {
+			rmq.Close()
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "os.Hostname")
+		}
+
+		deadLetter := rabbitmq.NewDeadLetter(rmq)
+
+		return rabbitmq.NewConsumer(rmq, deadLetter, consumerName, hostname, logger), rmq.Close, nil
+	case sourceRedis:
+		rdb, err := internal.NewRedis(conf)
+		if err != nil {
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewRedis")
+		}
+
+		hostname, err := os.Hostname()
+		if err != nil {
+			_ = rdb.Close()
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "os.Hostname")
+		}
+
+		checker.Add("redis", func(ctx context.Context) error {
+			return rdb.Ping(ctx).Err()
+		})
+
+		deadLetter := redis.NewDeadLetter(rdb)
+
+		return redis.NewConsumer(rdb, deadLetter, consumerName, hostname, logger), func() { _ = rdb.Close() }, nil
+	}
+
+	return nil, nil, internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "unknown source %q", source)
+}
+
+// Server consumes events from a message broker and serves metrics and health checks over HTTP.
+type Server struct {
+	logger   *zap.Logger
+	health   *health.Checker
+	consumer indexer.Consumer
+	handler  *indexer.Handler
+	metrics  *http.Server
+	doneC    chan struct{}
+	closeC   chan struct{}
+}
+
+// ListenAndServe consumes events and serves metrics and health checks, it blocks until consuming stops.
+func (s *Server) ListenAndServe() error {
+	defer close(s.doneC)
+
+	go func() {
+		if err := s.metrics.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Couldn't serve metrics and health checks", zap.Error(err))
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-s.closeC
+		cancel()
+	}()
+
+	if err := s.consumer.Consume(ctx, s.handler.Handle); err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "consumer.Consume")
+	}
+
+	s.logger.Info("No more messages to consume, Exiting.")
+
+	return nil
+}
+
+// Shutdown stops consuming events, waiting for the event being handled to complete, and stops serving metrics and
+// health checks. Readiness fails while waiting.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.logger.Info("Shutting down server")
+
+	s.health.Shutdown()
+
+	close(s.closeC)
+
+	select {
+	case <-ctx.Done():
+		_ = s.metrics.Close()
+
+		return internaldomain.WrapErrorf(ctx.Err(), internaldomain.ErrorCodeUnkown, "context.Done")
+	case <-s.doneC:
+	}
+
+	if err := s.metrics.Shutdown(ctx); err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "metrics.Shutdown")
+	}
+
+	return nil
+}
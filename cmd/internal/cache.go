@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	internaldomain "github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/cache"
+	envvar "github.com/sanLimbu/todo-api/internal/envar"
+	"github.com/sanLimbu/todo-api/internal/memcached"
+	"github.com/sanLimbu/todo-api/internal/redis"
+)
+
+const (
+	CacheBackendMemcached = "memcached"
+	CacheBackendRedis     = "redis"
+	CacheBackendMemory    = "memory"
+
+	defaultCacheLocalSize = 10_000
+)
+
+// CacheConfig defines the cache backend, a LocalSize greater than zero adds an in-process LRU of that size in front
+// of memcached or Redis.
+type CacheConfig struct {
+	Backend   string        `env:"CACHE_BACKEND" default:"memcached" oneof:"memcached redis memory"`
+	LocalSize int           `env:"CACHE_LOCAL_SIZE" default:"0"`
+	LocalTTL  time.Duration `env:"CACHE_LOCAL_TTL" default:"5s"`
+}
+
+// NewCache instantiates the cache defined in CACHE_BACKEND: memcached (default), redis or memory. When
+// CACHE_LOCAL_SIZE is set the shared backends use an in-process LRU of that size in front of them, the local values
+// are kept for CACHE_LOCAL_TTL. The returned function closes the connections used by the cache.
+func NewCache(conf *envvar.Configuration) (cache.Cache, func(), error) {
+	var cfg CacheConfig
+
+	if err := conf.Load(&cfg); err != nil {
+		return nil, nil, fmt.Errorf("conf.Load %w", err)
+	}
+
+	if cfg.LocalSize < 0 {
+		return nil, nil, internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "CACHE_LOCAL_SIZE must be zero or a positive number")
+	}
+
+	if cfg.LocalTTL <= 0 {
+		return nil, nil, internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "CACHE_LOCAL_TTL must be a positive duration")
+	}
+
+	localSize := cfg.LocalSize
+
+	var (
+		shared  cache.Cache
+		closeFn = func() {}
+	)
+
+	switch cfg.Backend {
+	case CacheBackendMemcached:
+		client, err := NewMemcached(conf)
+		if err != nil {
+			return nil, nil, fmt.Errorf("NewMemcached %w", err)
+		}
+
+		shared = memcached.NewCache(client)
+	case CacheBackendRedis:
+		client, err := NewRedis(conf)
+		if err != nil {
+			return nil, nil, fmt.Errorf("NewRedis %w", err)
+		}
+
+		shared = redis.NewCache(client)
+		closeFn = func() { _ = client.Close() }
+	case CacheBackendMemory:
+		if localSize == 0 {
+			localSize = defaultCacheLocalSize
+		}
+
+		return cache.NewLRU(localSize), closeFn, nil
+	}
+
+	if localSize == 0 {
+		return shared, closeFn, nil
+	}
+
+	return cache.NewTiered(cache.NewLRU(localSize), shared, cfg.LocalTTL), closeFn, nil
+}
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	internaldomain "github.com/sanLimbu/todo-api/internal"
+	envvar "github.com/sanLimbu/todo-api/internal/envar"
+)
+
+// defaulter is implemented by configuration sections with defaults depending on other settings.
+type defaulter interface {
+	applyDefaults()
+}
+
+// PrintConfig loads the configuration sections and writes their effective settings to w with secrets redacted. All
+// the sections are written even when some of them are invalid, in that case the validation errors are returned.
+func PrintConfig(w io.Writer, conf *envvar.Configuration, sections ...interface{}) error {
+	var errs []error
+
+	for _, section := range sections {
+		if err := conf.Load(section); err != nil {
+			errs = append(errs, err)
+		}
+
+		if d, ok := section.(defaulter); ok {
+			d.applyDefaults()
+		}
+	}
+
+	if err := envvar.Print(w, sections...); err != nil {
+		return fmt.Errorf("envvar.Print %w", err)
+	}
+
+	if len(errs) > 0 {
+		return internaldomain.WrapErrorf(errors.Join(errs...), internaldomain.ErrorCodeInvalidArgument, "conf.Load")
+	}
+
+	return nil
+}
@@ -6,10 +6,27 @@ import (
 	envvar "github.com/sanLimbu/todo-api/internal/envar"
 )
 
+// ElasticsearchConfig defines the settings used for connecting to Elasticsearch, multiple URLs are separated by commas.
+type ElasticsearchConfig struct {
+	URLs     []string `env:"ELASTICSEARCH_URL" default:"http://localhost:9200"`
+	Username string   `env:"ELASTICSEARCH_USERNAME"`
+	Password string   `env:"ELASTICSEARCH_PASSWORD" secret:"true"`
+}
+
 //NewElasticSearch instantiates the ElasticSearch client using configuration dfined in environment variables.
 
-func NewElasticSearch(_ *envvar.Configuration) (es *esv7.Client, err error) {
-	es, err = esv7.NewDefaultClient()
+func NewElasticSearch(conf *envvar.Configuration) (es *esv7.Client, err error) {
+	var cfg ElasticsearchConfig
+
+	if err := conf.Load(&cfg); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "conf.Load")
+	}
+
+	es, err = esv7.NewClient(esv7.Config{
+		Addresses: cfg.URLs,
+		Username:  cfg.Username,
+		Password:  cfg.Password,
+	})
 	if err != nil {
 		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "elasticsearch.Open")
 	}
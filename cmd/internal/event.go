@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	envvar "github.com/sanLimbu/todo-api/internal/envar"
+	"github.com/sanLimbu/todo-api/internal/event"
+	"github.com/sanLimbu/todo-api/internal/event/eventpb"
+	"github.com/sanLimbu/todo-api/internal/schemaregistry"
+)
+
+// EventConfig defines the settings used for serializing events, the default schema registry subject follows the
+// record name strategy and is used for all message brokers.
+type EventConfig struct {
+	Format                string `env:"EVENT_FORMAT" default:"json" oneof:"json protobuf"`
+	SchemaRegistryURL     string `env:"SCHEMA_REGISTRY_URL"`
+	SchemaRegistrySubject string `env:"SCHEMA_REGISTRY_SUBJECT" default:"tasks.events.v1.Envelope"`
+}
+
+// NewEventSerializer instantiates the serializer used for publishing events, EVENT_FORMAT selects "json", the
+// default, or "protobuf". Protobuf events use the schema registry wire format when SCHEMA_REGISTRY_URL is defined,
+// the schema is registered before returning.
+func NewEventSerializer(ctx context.Context, conf *envvar.Configuration) (event.Serializer, error) {
+	var cfg EventConfig
+
+	if err := conf.Load(&cfg); err != nil {
+		return nil, fmt.Errorf("conf.Load %w", err)
+	}
+
+	if cfg.Format == "json" {
+		return event.JSON{}, nil
+	}
+
+	if cfg.SchemaRegistryURL == "" {
+		return event.NewProtobuf(), nil
+	}
+
+	id, err := schemaregistry.NewClient(cfg.SchemaRegistryURL).Register(ctx, cfg.SchemaRegistrySubject, schemaregistry.SchemaTypeProtobuf, eventpb.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("schemaregistry.Register %w", err)
+	}
+
+	return event.NewProtobufSchemaRegistry(id), nil
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	esv7 "github.com/elastic/go-elasticsearch/v7"
+
+	internaldomain "github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/cache"
+	"github.com/sanLimbu/todo-api/internal/health"
+)
+
+// healthCheckKey is looked up for checking the cache, it is never set so a miss means the cache is reachable.
+const healthCheckKey = "health_check"
+
+// HealthConfig defines how dependencies are checked by the readiness endpoint, readiness fails for ShutdownDelay
+// before the server stops accepting connections so load balancers stop routing requests to it first.
+type HealthConfig struct {
+	Timeout       time.Duration `env:"HEALTH_CHECK_TIMEOUT" default:"500ms"`
+	CacheTTL      time.Duration `env:"HEALTH_CHECK_CACHE_TTL" default:"5s"`
+	ShutdownDelay time.Duration `env:"HEALTH_SHUTDOWN_DELAY" default:"0s"`
+}
+
+// ElasticsearchCheck pings the Elasticsearch cluster.
+func ElasticsearchCheck(client *esv7.Client) health.Check {
+	return func(ctx context.Context) error {
+		res, err := client.Ping(client.Ping.WithContext(ctx))
+		if err != nil {
+			return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "Ping")
+		}
+		defer res.Body.Close()
+
+		if res.IsError() {
+			return internaldomain.NewErrorf(internaldomain.ErrorCodeUnkown, "Ping: %s", res.Status())
+		}
+
+		return nil
+	}
+}
+
+// CacheCheck looks up a key that is never cached, a miss means the cache backend is reachable.
+func CacheCheck(c cache.Cache) health.Check {
+	return func(ctx context.Context) error {
+		if _, err := c.Get(ctx, healthCheckKey); err != nil && !errors.Is(err, cache.ErrMiss) {
+			return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "c.Get")
+		}
+
+		return nil
+	}
+}
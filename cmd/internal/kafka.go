@@ -2,25 +2,35 @@ package internal
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	internaldomain "github.com/sanLimbu/todo-api/internal"
 	envvar "github.com/sanLimbu/todo-api/internal/envar"
 )
 
+// kafkaFlushTimeout is how long closing the producer waits for outstanding messages to be delivered.
+const kafkaFlushTimeout = 5 * time.Second
+
 type KafkaProducer struct {
-	Producer *kafka.Producer
-	Topic    string
+	Producer        *kafka.Producer
+	Topic           string
+	DeadLetterTopic string
+	Sync            bool
 }
 
 //NewKafkaProducer instantiates the Kafka producer using configuration defined in environment variables.
 func NewKafkaProducer(conf *envvar.Configuration) (*KafkaProducer, error) {
-	host, topic, err := newKafkaConfig(conf)
+	cfg, err := newKafkaConfig(conf)
 	if err != nil {
-		return nil, fmt.Errorf("newKafkaConig %w", err)
+		return nil, fmt.Errorf("newKafkaConfig %w", err)
 	}
+
+	// The idempotent producer retries without duplicating nor reordering messages, it requires "acks=all".
 	config := kafka.ConfigMap{
-		"bootstrap.servers": host,
+		"bootstrap.servers":  cfg.Host,
+		"enable.idempotence": true,
+		"acks":               "all",
 	}
 
 	client, err := kafka.NewProducer(&config)
@@ -28,21 +38,55 @@ func NewKafkaProducer(conf *envvar.Configuration) (*KafkaProducer, error) {
 		return nil, fmt.Errorf("kafka.NewProducer %w", err)
 	}
 	return &KafkaProducer{
-		Producer: client,
-		Topic:    topic,
+		Producer:        client,
+		Topic:           cfg.Topic,
+		DeadLetterTopic: cfg.DeadLetterTopic,
+		Sync:            cfg.ProducerSync,
 	}, nil
 }
 
+// Close waits for outstanding messages to be delivered and closes the producer, it returns the number of messages
+// that were not delivered.
+func (k *KafkaProducer) Close() int {
+	res := k.Producer.Flush(int(kafkaFlushTimeout.Milliseconds()))
+	k.Producer.Close()
+
+	return res
+}
+
 type KafkaConsumer struct {
 	Consumer *kafka.Consumer
+	Workers  int
 }
 
 //NewKafkaConsumer instantiates the Kafka consumer using configuration defined in environment variables.
 func NewKafkaConsumer(conf *envvar.Configuration, groupID string) (*KafkaConsumer, error) {
-	host, topic, err := newKafkaConfig(conf)
+	cfg, err := newKafkaConfig(conf)
 	if err != nil {
 		return nil, fmt.Errorf("newKafkaConfig %w", err)
 	}
+
+	res, err := newKafkaConsumer(cfg.Host, cfg.Topic, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("newKafkaConsumer %w", err)
+	}
+
+	res.Workers = cfg.ConsumerWorkers
+
+	return res, nil
+}
+
+//NewKafkaDeadLetterConsumer instantiates the Kafka consumer subscribed to the dead letter topic.
+func NewKafkaDeadLetterConsumer(conf *envvar.Configuration, groupID string) (*KafkaConsumer, error) {
+	cfg, err := newKafkaConfig(conf)
+	if err != nil {
+		return nil, fmt.Errorf("newKafkaConfig %w", err)
+	}
+
+	return newKafkaConsumer(cfg.Host, cfg.DeadLetterTopic, groupID)
+}
+
+func newKafkaConsumer(host, topic, groupID string) (*KafkaConsumer, error) {
 	config := kafka.ConfigMap{
 		"bootstrap.servers":  host,
 		"group.id":           groupID,
@@ -62,19 +106,34 @@ func NewKafkaConsumer(conf *envvar.Configuration, groupID string) (*KafkaConsume
 	}, nil
 }
 
-func newKafkaConfig(conf *envvar.Configuration) (host, topic string, err error) {
-	host, err = conf.Get("KAFKA_HOST")
-	if err != nil {
-		return "", "", fmt.Errorf("conf.Get KAFKA_HOST %w", err)
+// KafkaConfig defines the settings used for connecting to Kafka, DeadLetterTopic defaults to
+// "<KAFKA_TOPIC>.dead-letter".
+type KafkaConfig struct {
+	Host            string `env:"KAFKA_HOST" required:"true"`
+	Topic           string `env:"KAFKA_TOPIC" required:"true"`
+	DeadLetterTopic string `env:"KAFKA_DEAD_LETTER_TOPIC"`
+	ProducerSync    bool   `env:"KAFKA_PRODUCER_SYNC" default:"false"`
+	ConsumerWorkers int    `env:"KAFKA_CONSUMER_WORKERS" default:"8"`
+}
+
+func (c *KafkaConfig) applyDefaults() {
+	if c.DeadLetterTopic == "" && c.Topic != "" {
+		c.DeadLetterTopic = c.Topic + ".dead-letter"
 	}
-	topic, err = conf.Get("KAFKA_TOPIC")
-	if err != nil {
-		return "", "", fmt.Errorf("conf.Get KAFKA_TOPIC %w", err)
+}
+
+func newKafkaConfig(conf *envvar.Configuration) (KafkaConfig, error) {
+	var cfg KafkaConfig
+
+	if err := conf.Load(&cfg); err != nil {
+		return KafkaConfig{}, fmt.Errorf("conf.Load %w", err)
 	}
 
-	if topic == "" {
-		return "", "", internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "KAFKA_TOPIC is required")
+	cfg.applyDefaults()
+
+	if cfg.ConsumerWorkers < 1 {
+		return KafkaConfig{}, internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "KAFKA_CONSUMER_WORKERS must be a positive number")
 	}
 
-	return host, topic, nil
+	return cfg, nil
 }
@@ -8,15 +8,20 @@ import (
 	envvar "github.com/sanLimbu/todo-api/internal/envar"
 )
 
+// MemcachedConfig defines the settings used for connecting to memcached.
+type MemcachedConfig struct {
+	Host string `env:"MEMCACHED_HOST" required:"true"`
+}
+
 func NewMemcached(conf *envvar.Configuration) (*memcache.Client, error) {
+	var cfg MemcachedConfig
 
-	host, err := conf.Get("MEMCACHED_HOST")
-	if err != nil {
-		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conf.Get MEMCACHED_HOST")
+	if err := conf.Load(&cfg); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "conf.Load")
 	}
 
 	// Assuming environment variable contains only one server
-	client := memcache.New(host)
+	client := memcache.New(cfg.Host)
 
 	if err := client.Ping(); err != nil {
 
@@ -1,76 +1,257 @@
 package internal
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
-
-	"github.com/sanLimbu/todo-api/internal"
-	envvar "github.com/sanLimbu/todo-api/internal/envar"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/prometheus"
-	"go.opentelemetry.io/otel/metric/global"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
-	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
-	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
-	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
-	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/sanLimbu/todo-api/internal"
+	envvar "github.com/sanLimbu/todo-api/internal/envar"
 )
 
-// NewOTExporter instantiates the OpenTelemetry exporters using configuration defined in environment variables.
-func NewOTExporter(conf *envvar.Configuration) (*prometheus.Exporter, error) {
+// Exporters and protocols supported by TelemetryConfig.
+const (
+	exporterOTLP       = "otlp"
+	exporterPrometheus = "prometheus"
+
+	protocolHTTP = "http/protobuf"
+)
+
+// serviceNamespace groups the services of this project.
+const serviceNamespace = "todo-api"
+
+// secondsBoundaries are the buckets of the histograms measured in seconds, the default ones are meant for
+// milliseconds.
+var secondsBoundaries = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// TelemetryConfig defines the settings used for exporting traces and metrics, names follow the OpenTelemetry SDK
+// environment variables. Resource attributes, like the service name, are overridden using OTEL_SERVICE_NAME and
+// OTEL_RESOURCE_ATTRIBUTES.
+type TelemetryConfig struct {
+	Disabled        bool     `env:"OTEL_SDK_DISABLED" default:"false"`
+	TracesExporter  string   `env:"OTEL_TRACES_EXPORTER" default:"otlp" oneof:"otlp none"`
+	MetricsExporter []string `env:"OTEL_METRICS_EXPORTER" default:"prometheus" oneof:"prometheus otlp none"`
+	Protocol        string   `env:"OTEL_EXPORTER_OTLP_PROTOCOL" default:"grpc" oneof:"grpc http/protobuf"`
+	Endpoint        string   `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	SamplerRatio    float64  `env:"OTEL_TRACES_SAMPLER_ARG" default:"1"`
+}
+
+// Telemetry holds the providers set globally, they must be shut down so buffered spans and metrics are exported.
+type Telemetry struct {
+	// Metrics serves the metrics in the Prometheus format, it responds with 404 when the Prometheus exporter is not
+	// enabled.
+	Metrics http.Handler
+
+	shutdowns []func(context.Context) error
+}
+
+// NewTelemetry sets the global OpenTelemetry providers using the configuration defined in environment variables,
+// serviceName identifies the binary in the exported data. Nothing is exported when OTEL_SDK_DISABLED is true.
+func NewTelemetry(conf *envvar.Configuration, serviceName string) (*Telemetry, error) {
+	var cfg TelemetryConfig
+
+	if err := conf.Load(&cfg); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "conf.Load")
+	}
+
+	if cfg.SamplerRatio < 0 || cfg.SamplerRatio > 1 {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "OTEL_TRACES_SAMPLER_ARG must be between 0 and 1")
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	res := &Telemetry{Metrics: http.NotFoundHandler()}
+
+	// The global providers are no-op until they are set.
+	if cfg.Disabled {
+		return res, nil
+	}
+
+	ctx := context.Background()
+
+	rsc, err := newResource(ctx, serviceName)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newResource")
+	}
+
+	if cfg.TracesExporter == exporterOTLP {
+		exporter, err := newTraceExporter(ctx, cfg)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newTraceExporter")
+		}
+
+		provider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(rsc),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplerRatio))),
+		)
+
+		otel.SetTracerProvider(provider)
+
+		res.shutdowns = append(res.shutdowns, provider.Shutdown)
+	}
+
+	var readers []sdkmetric.Reader
+
+	for _, name := range cfg.MetricsExporter {
+		switch name {
+		case exporterPrometheus:
+			registry := prom.NewRegistry()
+
+			// Units and suffixes are not added to the names so they match the ones of the instruments.
+			exporter, err := prometheus.New(
+				prometheus.WithRegisterer(registry),
+				prometheus.WithoutUnits(),
+				prometheus.WithoutCounterSuffixes(),
+			)
+			if err != nil {
+				return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "prometheus.New")
+			}
+
+			readers = append(readers, exporter)
+			res.Metrics = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+		case exporterOTLP:
+			exporter, err := newMetricExporter(ctx, cfg)
+			if err != nil {
+				return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newMetricExporter")
+			}
+
+			readers = append(readers, sdkmetric.NewPeriodicReader(exporter))
+		}
+	}
+
+	if len(readers) == 0 {
+		return res, nil
+	}
+
+	opts := []sdkmetric.Option{
+		sdkmetric.WithResource(rsc),
+		sdkmetric.WithView(sdkmetric.NewView(
+			sdkmetric.Instrument{Kind: sdkmetric.InstrumentKindHistogram, Unit: "s"},
+			sdkmetric.Stream{Aggregation: sdkmetric.AggregationExplicitBucketHistogram{Boundaries: secondsBoundaries}},
+		)),
+	}
+
+	for _, reader := range readers {
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+
+	provider := sdkmetric.NewMeterProvider(opts...)
+
+	otel.SetMeterProvider(provider)
+
+	res.shutdowns = append(res.shutdowns, provider.Shutdown)
 
 	if err := runtime.Start(runtime.WithMinimumReadMemStatsInterval(time.Second)); err != nil {
 		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "runtime.Start")
 	}
 
-	config := prometheus.Config{}
-	c := controller.New(
-		processor.NewFactory(
-			selector.NewWithHistogramDistribution(
-				histogram.WithExplicitBoundaries(config.DefaultHistogramBoundaries),
-			),
-			aggregation.CumulativeTemporalitySelector(),
-			processor.WithMemory(true),
+	return res, nil
+}
+
+// Shutdown exports the buffered spans and metrics, and stops the providers.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	for _, shutdown := range t.shutdowns {
+		if err := shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return internal.WrapErrorf(errors.Join(errs...), internal.ErrorCodeUnkown, "shutdown")
+	}
+
+	return nil
+}
+
+// newResource describes the running binary, attributes defined in the environment take precedence.
+func newResource(ctx context.Context, serviceName string) (*resource.Resource, error) {
+	version := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		version = info.Main.Version
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceNamespace(serviceNamespace),
+			semconv.ServiceVersion(version),
+			semconv.ServiceInstanceID(uuid.NewString()),
 		),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithFromEnv(),
 	)
-
-	//Set up prometheus exporter
-	promExporter, err := prometheus.New(config, c)
 	if err != nil {
-		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "prometheus.New")
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "resource.New")
+	}
 
+	return res, nil
+}
+
+func newTraceExporter(ctx context.Context, cfg TelemetryConfig) (sdktrace.SpanExporter, error) {
+	if cfg.Protocol == protocolHTTP {
+		var opts []otlptracehttp.Option
+
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(signalURL(cfg.Endpoint, "traces")))
+		}
+
+		return otlptracehttp.New(ctx, opts...)
 	}
 
-	global.SetMeterProvider(promExporter.MeterProvider())
+	var opts []otlptracegrpc.Option
 
-	//Set up Jaeger exporter
-	jaegerEndpoint, _ := conf.Get("JAEGER_ENDPOINT")
-	jaegerExporter, err := jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(jaegerEndpoint)))
-	if err != nil {
-		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "jaeger.New")
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
 	}
 
-	//Set up the trace provider with the Jaeger exporter
-	traceProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(jaegerExporter),
-		sdktrace.WithResource(resource.NewSchemaless(attribute.KeyValue{
-			Key:   semconv.ServiceNameKey,
-			Value: attribute.StringValue("rest-server"),
-		})),
-	)
+	return otlptracegrpc.New(ctx, opts...)
+}
 
-	otel.SetTracerProvider(traceProvider)
+func newMetricExporter(ctx context.Context, cfg TelemetryConfig) (sdkmetric.Exporter, error) {
+	if cfg.Protocol == protocolHTTP {
+		var opts []otlpmetrichttp.Option
 
-	//Set global propagator
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(signalURL(cfg.Endpoint, "metrics")))
+		}
+
+		return otlpmetrichttp.New(ctx, opts...)
+	}
 
-	return promExporter, nil
+	var opts []otlpmetricgrpc.Option
+
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlpmetricgrpc.WithEndpointURL(cfg.Endpoint))
+	}
+
+	return otlpmetricgrpc.New(ctx, opts...)
+}
 
+// signalURL returns the URL receiving the signal over HTTP, the endpoint is the base URL shared by all signals.
+func signalURL(endpoint, signal string) string {
+	return strings.TrimSuffix(endpoint, "/") + "/v1/" + signal
 }
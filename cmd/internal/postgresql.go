@@ -3,49 +3,76 @@ package internal
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/url"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/sanLimbu/todo-api/internal"
 	envvar "github.com/sanLimbu/todo-api/internal/envar"
+	"github.com/sanLimbu/todo-api/internal/envar/vault"
 )
 
-// NewPostgreSQL instantiates the PostgreSQL database using configuration defined in environment variables.
+// databaseCredentials issues short-lived database credentials, it is implemented by vault.Provider.
+type databaseCredentials interface {
+	DatabaseCredentials(ctx context.Context, role string) (vault.Credentials, error)
+}
+
+// PostgreSQLConfig defines the settings used for connecting to PostgreSQL, when VaultRole is set the credentials are
+// issued by the Vault database secrets engine instead.
+type PostgreSQLConfig struct {
+	Host      string `env:"DATABASE_HOST" default:"localhost"`
+	Port      int    `env:"DATABASE_PORT" default:"5432"`
+	Username  string `env:"DATABASE_USERNAME"`
+	Password  string `env:"DATABASE_PASSWORD" secret:"true"`
+	VaultRole string `env:"DATABASE_VAULT_ROLE"`
+	Name      string `env:"DATABASE_NAME" required:"true"`
+	SSLMode   string `env:"DATABASE_SSLMODE" default:"disable" oneof:"disable allow prefer require verify-ca verify-full"`
+}
+
+// NewPostgreSQL instantiates the PostgreSQL database using configuration defined in environment variables. Dynamic
+// credentials are rotated transparently: new connections use the current credentials and connections are closed
+// before their credentials expire.
 func NewPostgreSQL(conf *envvar.Configuration) (*pgxpool.Pool, error) {
-	get := func(v string) string {
-		res, err := conf.Get(v)
-		if err != nil {
-			log.Fatalf("Couldn't get configuration value for %s: %s", v, err)
-		}
+	var cfg PostgreSQLConfig
 
-		return res
+	if err := conf.Load(&cfg); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "conf.Load")
 	}
 
-	// XXX: We will revisit this code in future episodes replacing it with another solution
-	databaseHost := get("DATABASE_HOST")
-	databasePort := get("DATABASE_PORT")
-	databaseUsername := get("DATABASE_USERNAME")
-	databasePassword := get("DATABASE_PASSWORD")
-	databaseName := get("DATABASE_NAME")
-	databaseSSLMode := get("DATABASE_SSLMODE")
-	// XXX: -
+	if cfg.Username == "" && cfg.VaultRole == "" {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "either DATABASE_USERNAME or DATABASE_VAULT_ROLE is required")
+	}
 
 	dsn := url.URL{
 		Scheme: "postgres",
-		User:   url.UserPassword(databaseUsername, databasePassword),
-		Host:   fmt.Sprintf("%s:%s", databaseHost, databasePort),
-		Path:   databaseName,
+		Host:   fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Path:   cfg.Name,
+	}
+
+	if cfg.Username != "" {
+		dsn.User = url.UserPassword(cfg.Username, cfg.Password)
 	}
 
 	q := dsn.Query()
-	q.Add("sslmode", databaseSSLMode)
+	q.Add("sslmode", cfg.SSLMode)
 
 	dsn.RawQuery = q.Encode()
 
-	pool, err := pgxpool.New(context.Background(), dsn.String())
+	poolConf, err := pgxpool.ParseConfig(dsn.String())
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "pgxpool.ParseConfig")
+	}
+
+	if cfg.VaultRole != "" {
+		if err := useDatabaseCredentials(poolConf, conf, cfg.VaultRole); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "useDatabaseCredentials")
+		}
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConf)
 	if err != nil {
 		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "pgxpool.Connect")
 	}
@@ -56,3 +83,43 @@ func NewPostgreSQL(conf *envvar.Configuration) (*pgxpool.Pool, error) {
 
 	return pool, nil
 }
+
+// useDatabaseCredentials authenticates new connections using the credentials issued for role, connections are closed
+// after a third of the lease because new credentials are issued when less than that is left.
+func useDatabaseCredentials(poolConf *pgxpool.Config, conf *envvar.Configuration, role string) error {
+	provider := conf.Provider()
+	if chain, ok := provider.(*envvar.Chain); ok {
+		provider = chain.Provider(secretsProviderVault)
+	}
+
+	creds, ok := provider.(databaseCredentials)
+	if !ok {
+		return internal.NewErrorf(internal.ErrorCodeInvalidArgument, "DATABASE_VAULT_ROLE requires Vault")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	current, err := creds.DatabaseCredentials(ctx, role)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "DatabaseCredentials")
+	}
+
+	if lifetime := current.LeaseDuration / 3; lifetime > 0 && lifetime < poolConf.MaxConnLifetime {
+		poolConf.MaxConnLifetime = lifetime
+	}
+
+	poolConf.BeforeConnect = func(ctx context.Context, connConf *pgx.ConnConfig) error {
+		current, err := creds.DatabaseCredentials(ctx, role)
+		if err != nil {
+			return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "DatabaseCredentials")
+		}
+
+		connConf.User = current.Username
+		connConf.Password = current.Password
+
+		return nil
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	envvar "github.com/sanLimbu/todo-api/internal/envar"
+	"github.com/sanLimbu/todo-api/internal/envar/vault"
+)
+
+func TestUseDatabaseCredentials(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		issued int
+	)
+
+	// The database secrets engine issues new credentials every time they are read.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/database/creds/todo-api" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+
+			return
+		}
+
+		mu.Lock()
+		issued++
+		username := fmt.Sprintf("user-%d", issued)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_duration": 3,
+			"data": map[string]interface{}{
+				"username": username,
+				"password": "secret",
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	provider, err := vault.New(vault.Config{Address: srv.URL, DatabaseMount: "database"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("vault.New: %s", err)
+	}
+
+	chain := envvar.NewChain(secretsProviderVault)
+	chain.Register(secretsProviderVault, provider)
+	t.Cleanup(chain.Close)
+
+	poolConf, err := pgxpool.ParseConfig("postgres://localhost:5432/todo?sslmode=disable")
+	if err != nil {
+		t.Fatalf("pgxpool.ParseConfig: %s", err)
+	}
+
+	if err := useDatabaseCredentials(poolConf, envvar.New(chain), "todo-api"); err != nil {
+		t.Fatalf("useDatabaseCredentials: %s", err)
+	}
+
+	// Connections are closed before the credentials they use are replaced.
+	if poolConf.MaxConnLifetime != time.Second {
+		t.Fatalf("expected max connection lifetime of a third of the lease, got %s", poolConf.MaxConnLifetime)
+	}
+
+	connect := func() string {
+		connConf := poolConf.ConnConfig.Copy()
+
+		if err := poolConf.BeforeConnect(context.Background(), connConf); err != nil {
+			t.Fatalf("BeforeConnect: %s", err)
+		}
+
+		if connConf.Password != "secret" {
+			t.Fatalf("unexpected password %q", connConf.Password)
+		}
+
+		return connConf.User
+	}
+
+	if user := connect(); user != "user-1" {
+		t.Fatalf("expected the credentials issued when configuring the pool, got %s", user)
+	}
+
+	time.Sleep(2*time.Second + 100*time.Millisecond)
+
+	if user := connect(); user != "user-2" {
+		t.Fatalf("expected new connections to use rotated credentials, got %s", user)
+	}
+}
+
+func TestUseDatabaseCredentials_WithoutVault(t *testing.T) {
+	t.Parallel()
+
+	poolConf, err := pgxpool.ParseConfig("postgres://localhost:5432/todo?sslmode=disable")
+	if err != nil {
+		t.Fatalf("pgxpool.ParseConfig: %s", err)
+	}
+
+	if err := useDatabaseCredentials(poolConf, envvar.New(envvar.NewChain(secretsProviderVault)), "todo-api"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
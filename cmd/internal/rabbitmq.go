@@ -4,34 +4,35 @@ import (
 	"fmt"
 
 	envvar "github.com/sanLimbu/todo-api/internal/envar"
+	"github.com/sanLimbu/todo-api/internal/rabbitmq"
 	"github.com/streadway/amqp"
+	"go.uber.org/zap"
 )
 
-//RabbitMQ.....
-type RabbitMQ struct {
-	Connection *amqp.Connection
-	Channel    *amqp.Channel
+// RabbitMQConfig defines the settings used for connecting to RabbitMQ, the URL includes the credentials.
+type RabbitMQConfig struct {
+	URL string `env:"RABBITMQ_URL" required:"true" secret:"true"`
 }
 
-//NewRabbitMQ instantiates the RabbitMQ instances using configuration defined in environment variables.
-func NewRabbitMQ(conf *envvar.Configuration) (*RabbitMQ, error) {
-	url, err := conf.Get("RABBITMQ_URL")
+//NewRabbitMQ instantiates the RabbitMQ connection using configuration defined in environment variables, the
+//connection is re-dialed and the exchanges and queues declared again when it is closed.
+func NewRabbitMQ(conf *envvar.Configuration, logger *zap.Logger) (*rabbitmq.Connection, error) {
+	var cfg RabbitMQConfig
 
-	if err != nil {
-		return nil, fmt.Errorf("conf.get %w", err)
+	if err := conf.Load(&cfg); err != nil {
+		return nil, fmt.Errorf("conf.Load %w", err)
 	}
 
-	conn, err := amqp.Dial(url)
+	conn, err := rabbitmq.NewConnection(cfg.URL, declareRabbitMQ, logger)
 	if err != nil {
-		return nil, fmt.Errorf("amqp.dial %w", err)
+		return nil, fmt.Errorf("rabbitmq.NewConnection %w", err)
 	}
 
-	ch, err := conn.Channel()
-	if err != nil {
-		return nil, fmt.Errorf("conn.Channel %w", err)
-	}
+	return conn, nil
+}
 
-	err = ch.ExchangeDeclare(
+func declareRabbitMQ(ch *amqp.Channel) error {
+	err := ch.ExchangeDeclare(
 		"tasks", //name
 		"topic", //type
 		true,    //durable
@@ -41,25 +42,44 @@ func NewRabbitMQ(conf *envvar.Configuration) (*RabbitMQ, error) {
 		nil,     //arguments
 	)
 	if err != nil {
-		return nil, fmt.Errorf("ch.ExchangeDeclae %w", err)
+		return fmt.Errorf("ch.ExchangeDeclare %w", err)
 	}
 
-	if err := ch.Qos(
-		1,     //prefetch Count
-		0,     //prefetch Size
-		false, //global
-	); err != nil {
-		return nil, fmt.Errorf("ch.Qos %w", err)
+	err = ch.ExchangeDeclare(
+		rabbitmq.DeadLetterExchange, //name
+		"fanout",                    //type
+		true,                        //durable
+		false,                       //auto-delete
+		false,                       //internal
+		false,                       //noWait
+		nil,                         //arguments
+	)
+	if err != nil {
+		return fmt.Errorf("ch.ExchangeDeclare %w", err)
 	}
 
-	return &RabbitMQ{
-		Connection: conn,
-		Channel:    ch,
-	}, nil
+	_, err = ch.QueueDeclare(
+		rabbitmq.DeadLetterQueue, //name
+		true,                     //durable
+		false,                    //delete when unused
+		false,                    //exclusive
+		false,                    //no-wait
+		nil,                      //arguments
+	)
+	if err != nil {
+		return fmt.Errorf("ch.QueueDeclare %w", err)
+	}
 
-}
+	err = ch.QueueBind(
+		rabbitmq.DeadLetterQueue,    //queue name
+		"",                          //routing key
+		rabbitmq.DeadLetterExchange, //exchange
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("ch.QueueBind %w", err)
+	}
 
-// Close ...
-func (r *RabbitMQ) Close() {
-	r.Connection.Close()
+	return nil
 }
@@ -2,31 +2,29 @@ package internal
 
 import (
 	"context"
-	"strconv"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/sanLimbu/todo-api/internal"
 	envvar "github.com/sanLimbu/todo-api/internal/envar"
 )
 
+// RedisConfig defines the settings used for connecting to Redis.
+type RedisConfig struct {
+	Host string `env:"REDIS_HOST" required:"true"`
+	DB   int    `env:"REDIS_DB" default:"0"`
+}
+
 //NewRedis instantiates the Redis Client using configuration defined in env variables
 func NewRedis(conf *envvar.Configuration) (*redis.Client, error) {
+	var cfg RedisConfig
 
-	host, err := conf.Get("REDIS_HOST")
-	if err != nil {
-		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conf.Get REDIS_HOST")
+	if err := conf.Load(&cfg); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "conf.Load")
 	}
 
-	db, err := conf.Get("REDIS_DB")
-	if err != nil {
-		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conf.Get REDIS_DB")
-	}
-
-	dbi, _ := strconv.Atoi(db)
-
 	rdb := redis.NewClient(&redis.Options{
-		Addr: host,
-		DB:   dbi,
+		Addr: cfg.Host,
+		DB:   cfg.DB,
 	})
 	if _, err := rdb.Ping(context.Background()).Result(); err != nil {
 		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "rdb.Ping")
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+	envvar "github.com/sanLimbu/todo-api/internal/envar"
+	"github.com/sanLimbu/todo-api/internal/envar/awssm"
+	"github.com/sanLimbu/todo-api/internal/envar/file"
+	"github.com/sanLimbu/todo-api/internal/envar/sops"
+)
+
+const (
+	secretsProviderVault = "vault"
+	secretsProviderFile  = "file"
+	secretsProviderSOPS  = "sops"
+	secretsProviderAWSSM = "awssm"
+)
+
+// SecretsConfig defines the providers used for getting the values of `<key>_SECURE` variables, the provider is
+// selected by the scheme of the value: "vault://", "file://", "sops://" or "awssm://"; values without scheme use
+// SECRETS_DEFAULT_PROVIDER. Providers other than "file" are used only when configured: Vault when VAULT_ADDRESS is
+// set, SOPS when an age key is set and Secrets Manager when AWS_REGION or AWS_SECRETS_MANAGER_ENDPOINT is set.
+type SecretsConfig struct {
+	DefaultProvider           string        `env:"SECRETS_DEFAULT_PROVIDER" default:"vault" oneof:"vault file sops awssm"`
+	FileDir                   string        `env:"SECRETS_FILE_DIR" default:"/run/secrets"`
+	SOPSAgeKey                string        `env:"SOPS_AGE_KEY" secret:"true"`
+	SOPSAgeKeyFile            string        `env:"SOPS_AGE_KEY_FILE"`
+	AWSRegion                 string        `env:"AWS_REGION"`
+	AWSSecretsManagerEndpoint string        `env:"AWS_SECRETS_MANAGER_ENDPOINT"`
+	AWSSecretsManagerRefresh  time.Duration `env:"AWS_SECRETS_MANAGER_REFRESH" default:"5m"`
+	Vault                     VaultConfig
+}
+
+// NewSecretsProvider instantiates the providers configured in environment variables, the returned Chain must be
+// closed for stopping renewing the Vault token.
+func NewSecretsProvider(logger *zap.Logger) (*envvar.Chain, error) {
+	var cfg SecretsConfig
+
+	if err := envvar.New(nil).Load(&cfg); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "envvar.Load")
+	}
+
+	chain := envvar.NewChain(cfg.DefaultProvider)
+
+	chain.Register(secretsProviderFile, file.New(cfg.FileDir))
+
+	if cfg.SOPSAgeKey != "" || cfg.SOPSAgeKeyFile != "" {
+		provider, err := newSOPSProvider(cfg)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newSOPSProvider")
+		}
+
+		chain.Register(secretsProviderSOPS, provider)
+	}
+
+	if cfg.AWSRegion != "" || cfg.AWSSecretsManagerEndpoint != "" {
+		provider, err := awssm.New(cfg.AWSRegion, cfg.AWSSecretsManagerEndpoint, cfg.AWSSecretsManagerRefresh, logger)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "awssm.New")
+		}
+
+		chain.Register(secretsProviderAWSSM, provider)
+	}
+
+	// Vault is the last one because it starts renewing the token.
+	if cfg.Vault.Address != "" {
+		provider, err := newVaultProvider(cfg.Vault, logger)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newVaultProvider")
+		}
+
+		chain.Register(secretsProviderVault, provider)
+	}
+
+	return chain, nil
+}
+
+// newSOPSProvider uses the age keys in SOPS_AGE_KEY and SOPS_AGE_KEY_FILE, like SOPS itself.
+func newSOPSProvider(cfg SecretsConfig) (*sops.Provider, error) {
+	keys := cfg.SOPSAgeKey
+
+	if cfg.SOPSAgeKeyFile != "" {
+		b, err := os.ReadFile(cfg.SOPSAgeKeyFile)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "os.ReadFile")
+		}
+
+		keys += "\n" + string(b)
+	}
+
+	provider, err := sops.New(strings.NewReader(keys))
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "sops.New")
+	}
+
+	return provider, nil
+}
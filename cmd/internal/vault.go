@@ -1,19 +1,70 @@
 package internal
 
 import (
-	"os"
+	"time"
+
+	"go.uber.org/zap"
 
 	"github.com/sanLimbu/todo-api/internal"
 	"github.com/sanLimbu/todo-api/internal/envar/vault"
 )
 
-//NewVaultProvider instantiate the Vault client using configuration defined in environment variables.
-func NewVaultProvider() (*vault.Provider, error) {
-	vaultPath := os.Getenv("VAULT_PATH")
-	vaultToken := os.Getenv("VAULT_TOKEN")
-	vaultAddress := os.Getenv("VAULT_ADDRESS")
+const (
+	vaultAuthToken      = "token"
+	vaultAuthAppRole    = "approle"
+	vaultAuthKubernetes = "kubernetes"
+)
+
+// VaultConfig defines the settings used for connecting to Vault, they can't be read from Vault itself. Vault is used
+// only when VAULT_ADDRESS is set, VAULT_AUTH_MOUNT defaults to the name of the auth method.
+type VaultConfig struct {
+	Address             string        `env:"VAULT_ADDRESS"`
+	AuthMethod          string        `env:"VAULT_AUTH_METHOD" default:"token" oneof:"token approle kubernetes"`
+	AuthMount           string        `env:"VAULT_AUTH_MOUNT"`
+	Token               string        `env:"VAULT_TOKEN" secret:"true"`
+	AppRoleRoleID       string        `env:"VAULT_APPROLE_ROLE_ID"`
+	AppRoleSecretID     string        `env:"VAULT_APPROLE_SECRET_ID" secret:"true"`
+	KubernetesRole      string        `env:"VAULT_KUBERNETES_ROLE"`
+	KubernetesTokenPath string        `env:"VAULT_KUBERNETES_TOKEN_PATH" default:"/var/run/secrets/kubernetes.io/serviceaccount/token"`
+	Path                string        `env:"VAULT_PATH"`
+	KVRefresh           time.Duration `env:"VAULT_KV_REFRESH" default:"5m"`
+	DatabaseMount       string        `env:"VAULT_DATABASE_MOUNT" default:"database"`
+}
+
+//newVaultProvider instantiate the Vault client, the token is renewed in the background until the provider is closed.
+func newVaultProvider(cfg VaultConfig, logger *zap.Logger) (*vault.Provider, error) {
+	conf := vault.Config{
+		Address:       cfg.Address,
+		Token:         cfg.Token,
+		Path:          cfg.Path,
+		KVRefresh:     cfg.KVRefresh,
+		DatabaseMount: cfg.DatabaseMount,
+	}
+
+	switch cfg.AuthMethod {
+	case vaultAuthAppRole:
+		if cfg.AppRoleRoleID == "" || cfg.AppRoleSecretID == "" {
+			return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "VAULT_APPROLE_ROLE_ID and VAULT_APPROLE_SECRET_ID are required")
+		}
+
+		conf.Auth = vault.AppRole{
+			Mount:    cfg.AuthMount,
+			RoleID:   cfg.AppRoleRoleID,
+			SecretID: cfg.AppRoleSecretID,
+		}
+	case vaultAuthKubernetes:
+		if cfg.KubernetesRole == "" {
+			return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "VAULT_KUBERNETES_ROLE is required")
+		}
+
+		conf.Auth = vault.Kubernetes{
+			Mount:     cfg.AuthMount,
+			Role:      cfg.KubernetesRole,
+			TokenPath: cfg.KubernetesTokenPath,
+		}
+	}
 
-	provider, err := vault.New(vaultToken, vaultAddress, vaultPath)
+	provider, err := vault.New(conf, logger)
 	if err != nil {
 		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "vault.New ")
 
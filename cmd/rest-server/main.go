@@ -12,7 +12,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/didip/tollbooth/v6"
 	"github.com/didip/tollbooth/v6/limiter"
 	esv7 "github.com/elastic/go-elasticsearch/v7"
@@ -23,10 +22,16 @@ import (
 	"github.com/riandyrn/otelchi"
 	"github.com/sanLimbu/todo-api/cmd/internal"
 	internaldomain "github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/cache"
 	"github.com/sanLimbu/todo-api/internal/elasticsearch"
 	envvar "github.com/sanLimbu/todo-api/internal/envar"
-	"github.com/sanLimbu/todo-api/internal/memcached"
+	"github.com/sanLimbu/todo-api/internal/event"
+	"github.com/sanLimbu/todo-api/internal/fanout"
+	"github.com/sanLimbu/todo-api/internal/health"
+	"github.com/sanLimbu/todo-api/internal/kafka"
+	"github.com/sanLimbu/todo-api/internal/memory"
 	"github.com/sanLimbu/todo-api/internal/postgresql"
+	"github.com/sanLimbu/todo-api/internal/rabbitmq"
 	"github.com/sanLimbu/todo-api/internal/redis"
 	"github.com/sanLimbu/todo-api/internal/rest"
 	"github.com/sanLimbu/todo-api/internal/service"
@@ -35,14 +40,52 @@ import (
 
 var content embed.FS
 
+const (
+	searchBackendElasticsearch = "elasticsearch"
+	searchBackendPostgreSQL    = "postgresql"
+)
+
+const (
+	taskStorageTable  = "table"
+	taskStorageEvents = "events"
+)
+
+const (
+	messageBrokerKafka    = "kafka"
+	messageBrokerNone     = "none"
+	messageBrokerRabbitMQ = "rabbitmq"
+	messageBrokerRedis    = "redis"
+)
+
+// settings defines how the server stores, searches and publishes tasks.
+type settings struct {
+	SearchBackend  string   `env:"SEARCH_BACKEND" default:"elasticsearch" oneof:"elasticsearch postgresql"`
+	TaskStorage    string   `env:"TASK_STORAGE" default:"table" oneof:"table events"`
+	MessageBrokers []string `env:"MESSAGE_BROKER" default:"redis" oneof:"kafka rabbitmq redis none"`
+}
+
+// backendMemory indicates all repositories are kept in memory, no external services are required.
+const backendMemory = "memory"
+
 func main() {
-	var env, address string
+	var env, address, backend string
+	var printCfg bool
 
 	flag.StringVar(&env, "env", "", "Environment Variables filename")
 	flag.StringVar(&address, "address", ":9234", "HTTP Server Address")
+	flag.StringVar(&backend, "backend", "", `Backend used for storing tasks, use "memory" for running without external services`)
+	flag.BoolVar(&printCfg, "print-config", false, "Prints the effective configuration with secrets redacted and exits")
 	flag.Parse()
 
-	errC, err := run(env, address)
+	if printCfg {
+		if err := printConfig(env, backend); err != nil {
+			log.Fatalf("Couldn't print configuration: %s", err)
+		}
+
+		return
+	}
+
+	errC, err := run(env, address, backend)
 	if err != nil {
 		log.Fatalf("Couldn't run: %s", err)
 	}
@@ -53,57 +96,49 @@ func main() {
 
 }
 
-func run(env, address string) (<-chan error, error) {
+func run(env, address, backend string) (<-chan error, error) {
 	logger, err := zap.NewProduction()
 	if err != nil {
 		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "zap.newProduction")
 	}
 
-	if err := envvar.Load(env); err != nil {
-		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "envar.load")
-
-	}
-
-	vault, err := internal.NewVaultProvider()
+	conf, closeConf, err := newConfiguration(env, backend, logger)
 	if err != nil {
-		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewVaultProvider")
+		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "newConfiguration")
 	}
 
-	conf := envvar.New(vault)
-
-	pool, err := internal.NewPostgreSQL(conf)
-	if err != nil {
-		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewPostgreSQl")
+	var srvConf serverConfig
 
+	switch backend {
+	case backendMemory:
+	case "":
+		srvConf, err = newServicesConfig(conf, logger)
+		if err != nil {
+			return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "newServicesConfig")
+		}
+	default:
+		return nil, internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "unknown backend %q", backend)
 	}
 
-	es, err := internal.NewElasticSearch(conf)
+	telemetry, err := internal.NewTelemetry(conf, "rest-server")
 	if err != nil {
-		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewElasticSearch")
+		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewTelemetry")
 	}
 
-	// kafka, err := internal.NewKafkaProducer(conf)
-	// if err != nil {
-	// 	return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewKafkaProducer")
-	// }
+	var healthCfg internal.HealthConfig
 
-	rdb, err := internal.NewRedis(conf)
-	if err != nil {
-		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewRedis")
+	if err := conf.Load(&healthCfg); err != nil {
+		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeInvalidArgument, "conf.Load")
 	}
 
-	memcached, err := internal.NewMemcached(conf)
-	if err != nil {
-		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewMemcached")
+	if srvConf.DB != nil {
+		if err := postgresql.ObserveOpenTasks(srvConf.DB); err != nil {
+			return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "postgresql.ObserveOpenTasks")
+		}
 	}
 
-	// rmq, err := internal.NewRabbitMQ(conf)
-	// if err != nil {
-	// 	return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewRabbitMQ")
-	// }
-	promExporter, err := internal.NewOTExporter(conf)
-	if err != nil {
-		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewOTExporter")
+	if srvConf.Kafka != nil {
+		go kafka.ReportDeliveries(srvConf.Kafka.Producer, logger)
 	}
 
 	logging := func(h http.Handler) http.Handler {
@@ -116,18 +151,14 @@ func run(env, address string) (<-chan error, error) {
 		})
 	}
 
-	srv, err := newServer(serverConfig{
-		Address:       address,
-		DB:            pool,
-		ElasticSearch: es,
-		//	Kafka:         kafka,
-		//RabbitMQ:    rmq,
-		Metrics:     promExporter,
-		Middlewares: []func(next http.Handler) http.Handler{otelchi.Middleware("todo-api-server"), logging},
-		Redis:       rdb,
-		Logger:      logger,
-		Memcached:   memcached,
-	})
+	srvConf.Address = address
+	srvConf.Backend = backend
+	srvConf.Metrics = telemetry.Metrics
+	srvConf.Middlewares = []func(next http.Handler) http.Handler{otelchi.Middleware("todo-api-server"), rest.NewMetricsMiddleware(), logging}
+	srvConf.Logger = logger
+	srvConf.Health = newHealthChecker(healthCfg, srvConf)
+
+	srv, err := newServer(srvConf)
 
 	if err != nil {
 		return nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "newServer")
@@ -145,12 +176,42 @@ func run(env, address string) (<-chan error, error) {
 		<-ctx.Done()
 		logger.Info("Shutdown signal received")
 
+		srvConf.Health.Shutdown()
+
+		if healthCfg.ShutdownDelay > 0 {
+			logger.Info("Waiting before shutting down", zap.Duration("delay", healthCfg.ShutdownDelay))
+			time.Sleep(healthCfg.ShutdownDelay)
+		}
+
 		ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 
 		defer func() {
 			_ = logger.Sync()
-			pool.Close()
-			rdb.Close()
+
+			if srvConf.DB != nil {
+				srvConf.DB.Close()
+			}
+
+			if srvConf.Redis != nil {
+				srvConf.Redis.Close()
+			}
+
+			if srvConf.CloseCache != nil {
+				srvConf.CloseCache()
+			}
+
+			closeConf()
+
+			if srvConf.Kafka != nil {
+				if n := srvConf.Kafka.Close(); n > 0 {
+					logger.Error("Kafka messages not delivered", zap.Int("count", n))
+				}
+			}
+
+			if srvConf.RabbitMQ != nil {
+				srvConf.RabbitMQ.Close()
+			}
+
 			stop()
 			cancel()
 			close(errC)
@@ -161,6 +222,12 @@ func run(env, address string) (<-chan error, error) {
 		if err := srv.Shutdown(ctxTimeout); err != nil {
 			errC <- err
 		}
+
+		// Spans of the requests completed while shutting down are exported as well.
+		if err := telemetry.Shutdown(ctxTimeout); err != nil {
+			logger.Error("Couldn't export telemetry", zap.Error(err))
+		}
+
 		logger.Info("shutdown completed")
 
 	}()
@@ -180,17 +247,227 @@ func run(env, address string) (<-chan error, error) {
 
 }
 
+// newConfiguration loads the env file, it is optional when using the memory backend. The returned function closes
+// the secrets providers.
+func newConfiguration(env, backend string, logger *zap.Logger) (*envvar.Configuration, func(), error) {
+	if backend != backendMemory || env != "" {
+		if err := envvar.Load(env); err != nil {
+			return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "envar.load")
+		}
+	}
+
+	secrets, err := internal.NewSecretsProvider(logger)
+	if err != nil {
+		return nil, nil, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewSecretsProvider")
+	}
+
+	return envvar.New(secrets), secrets.Close, nil
+}
+
+// printConfig prints the settings of the services used by the server given the selected backends.
+func printConfig(env, backend string) error {
+	conf, closeConf, err := newConfiguration(env, backend, zap.NewNop())
+	if err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "newConfiguration")
+	}
+	defer closeConf()
+
+	sections := []interface{}{&internal.SecretsConfig{}, &internal.TelemetryConfig{}, &internal.HealthConfig{}}
+
+	if backend == backendMemory {
+		return internal.PrintConfig(os.Stdout, conf, sections...)
+	}
+
+	// Invalid values are reported when printing, here they only select the sections to print.
+	var (
+		set      settings
+		cacheCfg internal.CacheConfig
+	)
+
+	_ = conf.Load(&set)
+	_ = conf.Load(&cacheCfg)
+
+	sections = append(sections, &set, &internal.PostgreSQLConfig{})
+
+	if set.SearchBackend == searchBackendElasticsearch {
+		sections = append(sections, &internal.ElasticsearchConfig{})
+	}
+
+	brokers, _ := newMessageBrokers(set.MessageBrokers)
+
+	usesRedis := cacheCfg.Backend == internal.CacheBackendRedis
+
+	for _, name := range brokers {
+		switch name {
+		case messageBrokerKafka:
+			sections = append(sections, &internal.KafkaConfig{})
+		case messageBrokerRabbitMQ:
+			sections = append(sections, &internal.RabbitMQConfig{})
+		case messageBrokerRedis:
+			usesRedis = true
+		}
+	}
+
+	if len(brokers) > 0 {
+		sections = append(sections, &internal.EventConfig{})
+	}
+
+	sections = append(sections, &cacheCfg)
+
+	if cacheCfg.Backend == internal.CacheBackendMemcached {
+		sections = append(sections, &internal.MemcachedConfig{})
+	}
+
+	if usesRedis {
+		sections = append(sections, &internal.RedisConfig{})
+	}
+
+	return internal.PrintConfig(os.Stdout, conf, sections...)
+}
+
+// newServicesConfig connects to the external services used by the server.
+func newServicesConfig(conf *envvar.Configuration, logger *zap.Logger) (serverConfig, error) {
+	pool, err := internal.NewPostgreSQL(conf)
+	if err != nil {
+		return serverConfig{}, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewPostgreSQl")
+	}
+
+	var set settings
+
+	if err := conf.Load(&set); err != nil {
+		return serverConfig{}, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeInvalidArgument, "conf.Load")
+	}
+
+	var es *esv7.Client
+
+	if set.SearchBackend == searchBackendElasticsearch {
+		es, err = internal.NewElasticSearch(conf)
+		if err != nil {
+			return serverConfig{}, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewElasticSearch")
+		}
+	}
+
+	c, closeCache, err := internal.NewCache(conf)
+	if err != nil {
+		return serverConfig{}, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewCache")
+	}
+
+	res := serverConfig{
+		DB:            pool,
+		ElasticSearch: es,
+		SearchBackend: set.SearchBackend,
+		TaskStorage:   set.TaskStorage,
+		Cache:         c,
+		CloseCache:    closeCache,
+	}
+
+	res.MessageBrokers, err = newMessageBrokers(set.MessageBrokers)
+	if err != nil {
+		return serverConfig{}, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "newMessageBrokers")
+	}
+
+	if len(res.MessageBrokers) > 0 {
+		res.EventSerializer, err = internal.NewEventSerializer(context.Background(), conf)
+		if err != nil {
+			return serverConfig{}, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewEventSerializer")
+		}
+	}
+
+	// Only the services used by the selected message brokers are connected to.
+	for _, name := range res.MessageBrokers {
+		switch name {
+		case messageBrokerKafka:
+			res.Kafka, err = internal.NewKafkaProducer(conf)
+			if err != nil {
+				return serverConfig{}, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewKafkaProducer")
+			}
+		case messageBrokerRabbitMQ:
+			res.RabbitMQ, err = internal.NewRabbitMQ(conf, logger)
+			if err != nil {
+				return serverConfig{}, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewRabbitMQ")
+			}
+		case messageBrokerRedis:
+			res.Redis, err = internal.NewRedis(conf)
+			if err != nil {
+				return serverConfig{}, internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewRedis")
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// newMessageBrokers returns the message brokers defined in MESSAGE_BROKER without duplicates, multiple values
+// publish events to all of them; "none" disables publishing events.
+func newMessageBrokers(names []string) ([]string, error) {
+	var res []string
+
+	seen := make(map[string]struct{})
+
+	for _, name := range names {
+		if name == messageBrokerNone {
+			if len(names) > 1 {
+				return nil, internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "MESSAGE_BROKER %q can't be combined with other values", messageBrokerNone)
+			}
+
+			return nil, nil
+		}
+
+		if _, ok := seen[name]; ok {
+			continue
+		}
+
+		seen[name] = struct{}{}
+
+		res = append(res, name)
+	}
+
+	return res, nil
+}
+
+// newHealthChecker checks the external services the server connected to, none when using the memory backend.
+func newHealthChecker(cfg internal.HealthConfig, conf serverConfig) *health.Checker {
+	checker := health.NewChecker(cfg.Timeout, cfg.CacheTTL)
+
+	if conf.DB != nil {
+		checker.Add("postgresql", conf.DB.Ping)
+	}
+
+	if conf.ElasticSearch != nil {
+		checker.Add("elasticsearch", internal.ElasticsearchCheck(conf.ElasticSearch))
+	}
+
+	if conf.Redis != nil {
+		checker.Add("redis", func(ctx context.Context) error {
+			return conf.Redis.Ping(ctx).Err()
+		})
+	}
+
+	if conf.Cache != nil {
+		checker.Add("cache", internal.CacheCheck(conf.Cache))
+	}
+
+	return checker
+}
+
 type serverConfig struct {
-	Address       string
-	DB            *pgxpool.Pool
-	ElasticSearch *esv7.Client
-	Kafka         *internal.KafkaProducer
-	RabbitMQ      *internal.RabbitMQ
-	Redis         *rv8.Client
-	Metrics       http.Handler
-	Middlewares   []func(next http.Handler) http.Handler
-	Logger        *zap.Logger
-	Memcached     *memcache.Client
+	Address         string
+	Backend         string
+	DB              *pgxpool.Pool
+	ElasticSearch   *esv7.Client
+	SearchBackend   string
+	TaskStorage     string
+	MessageBrokers  []string
+	EventSerializer event.Serializer
+	Kafka           *internal.KafkaProducer
+	RabbitMQ        *rabbitmq.Connection
+	Redis           *rv8.Client
+	Metrics         http.Handler
+	Middlewares     []func(next http.Handler) http.Handler
+	Logger          *zap.Logger
+	Cache           cache.Cache
+	CloseCache      func()
+	Health          *health.Checker
 }
 
 func newServer(conf serverConfig) (*http.Server, error) {
@@ -200,23 +477,68 @@ func newServer(conf serverConfig) (*http.Server, error) {
 		router.Use(mw)
 	}
 
-	repo := postgresql.NewTask(conf.DB)
-	mrepo := memcached.NewTask(conf.Memcached, repo, conf.Logger)
+	var (
+		repo      service.TaskRepository
+		search    service.TaskSearchRepository
+		msgBroker service.TaskMessageBrokerRepository
+	)
 
-	search := elasticsearch.NewTask(conf.ElasticSearch)
-	msearch := memcached.NewSearchableTask(conf.Memcached, search)
+	switch conf.Backend {
+	case backendMemory:
+		msearch := memory.NewSearchableTask()
+		broker := memory.NewTaskMessageBroker()
 
-	// msgBroker, err := rabbitmq.NewTask(conf.RabbitMQ.Channel)
-	// if err != nil {
-	// 	return nil, fmt.Errorf("rabbitmq.NewTask %w", err)
-	// }
+		// Replaces the Elasticsearch indexer by keeping the searchable tasks up to date in process.
+		broker.Subscribe(func(ctx context.Context, evt memory.Event) error {
+			if evt.Type == memory.EventTypeTaskDeleted {
+				return msearch.Delete(ctx, evt.Task.ID, evt.Task.Version)
+			}
 
-	msgBroker := redis.NewTask(conf.Redis)
+			return msearch.Index(ctx, evt.Task)
+		})
 
-	svc := service.NewTask(conf.Logger, mrepo, msearch, msgBroker)
+		repo, search, msgBroker = memory.NewTask(), msearch, broker
+	default:
+		var taskStore cache.TaskStore = postgresql.NewTask(conf.DB)
+
+		if conf.TaskStorage == taskStorageEvents {
+			taskStore = postgresql.NewEventSourcedTask(conf.DB)
+		}
+
+		repo = cache.NewTask(conf.Cache, taskStore, conf.Logger)
+
+		var store cache.SearchableTaskStore
+
+		switch conf.SearchBackend {
+		case searchBackendPostgreSQL:
+			store = postgresql.NewSearchableTask(conf.DB)
+		default:
+			store = elasticsearch.NewTask(conf.ElasticSearch)
+		}
 
-	// msgBroker := kafka.NewTask(conf.Kafka.Producer, conf.Kafka.Topic)
-	// svc := service.NewTask(conf.Logger, repo, search, msgBroker)
+		search = cache.NewSearchableTask(conf.Cache, store, conf.Logger)
+
+		brokers := make([]service.TaskMessageBrokerRepository, 0, len(conf.MessageBrokers))
+
+		for _, name := range conf.MessageBrokers {
+			switch name {
+			case messageBrokerKafka:
+				brokers = append(brokers, kafka.NewTask(conf.Kafka.Producer, conf.EventSerializer, conf.Kafka.Topic, conf.Kafka.Sync))
+			case messageBrokerRabbitMQ:
+				brokers = append(brokers, rabbitmq.NewTask(conf.RabbitMQ, conf.EventSerializer))
+			case messageBrokerRedis:
+				brokers = append(brokers, redis.NewTask(conf.Redis, conf.EventSerializer))
+			}
+		}
+
+		if len(brokers) == 1 {
+			msgBroker = brokers[0]
+		} else {
+			msgBroker = fanout.NewTask(brokers...)
+		}
+	}
+
+	svc := service.NewTask(conf.Logger, repo, search, msgBroker)
 
 	rest.RegisterOpenAPI(router)
 	rest.NewTaskHandler(svc).Register(router)
@@ -228,8 +550,13 @@ func newServer(conf serverConfig) (*http.Server, error) {
 	lmt := tollbooth.NewLimiter(3, &limiter.ExpirableOptions{DefaultExpirationTTL: time.Second})
 	lmtmw := tollbooth.LimitHandler(lmt, router)
 
+	// Health endpoints are not rate limited nor logged, probes are frequent and must not be rejected.
+	mux := http.NewServeMux()
+	conf.Health.Register(mux)
+	mux.Handle("/", lmtmw)
+
 	return &http.Server{
-		Handler:           lmtmw,
+		Handler:           mux,
 		Addr:              conf.Address,
 		ReadTimeout:       1 * time.Second,
 		ReadHeaderTimeout: 1 * time.Second,
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sanLimbu/todo-api/cmd/internal"
+	internaldomain "github.com/sanLimbu/todo-api/internal"
+	envvar "github.com/sanLimbu/todo-api/internal/envar"
+	"github.com/sanLimbu/todo-api/internal/postgresql"
+	"go.uber.org/zap"
+)
+
+func main() {
+	var env, id, at string
+	var rebuild, force bool
+
+	flag.StringVar(&env, "env", "", "Environment Variables filename")
+	flag.StringVar(&id, "id", "", "Task ID, prints all its events unless -at is used")
+	flag.StringVar(&at, "at", "", "Prints the task as it was at this RFC 3339 time, requires -id")
+	flag.BoolVar(&rebuild, "rebuild", false, "Rebuilds the tasks table from all the events")
+	flag.BoolVar(&force, "force", false, "Rebuilds even when tasks without events would be removed, requires -rebuild")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(),
+		os.Interrupt,
+		syscall.SIGTERM,
+		syscall.SIGQUIT)
+	defer stop()
+
+	if err := run(ctx, env, id, at, rebuild, force); err != nil {
+		log.Fatalf("Couldn't run: %s", err)
+	}
+}
+
+func run(ctx context.Context, env, id, at string, rebuild, force bool) error {
+	if !rebuild && id == "" {
+		return internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "either -id or -rebuild is required")
+	}
+
+	if force && !rebuild {
+		return internaldomain.NewErrorf(internaldomain.ErrorCodeInvalidArgument, "-force requires -rebuild")
+	}
+
+	if err := envvar.Load(env); err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "envvar.Load")
+	}
+
+	secrets, err := internal.NewSecretsProvider(zap.NewNop())
+	if err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewSecretsProvider")
+	}
+	defer secrets.Close()
+
+	conf := envvar.New(secrets)
+
+	pool, err := internal.NewPostgreSQL(conf)
+	if err != nil {
+		return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "internal.NewPostgreSQL")
+	}
+	defer pool.Close()
+
+	repo := postgresql.NewEventSourcedTask(pool)
+	enc := json.NewEncoder(os.Stdout)
+
+	switch {
+	case rebuild:
+		if err := repo.RebuildProjection(ctx, force); err != nil {
+			return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "RebuildProjection")
+		}
+
+		fmt.Println("Rebuilt tasks table")
+	case at != "":
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeInvalidArgument, "time.Parse")
+		}
+
+		task, err := repo.FindAt(ctx, id, t)
+		if err != nil {
+			return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "FindAt")
+		}
+
+		if err := enc.Encode(newOutput(task)); err != nil {
+			return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "enc.Encode")
+		}
+	default:
+		evts, err := repo.History(ctx, id)
+		if err != nil {
+			return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "History")
+		}
+
+		for _, evt := range evts {
+			res := newOutput(evt.Task)
+			res.Position = evt.Position
+			res.Type = evt.Type
+			res.OccurredAt = &evt.OccurredAt
+
+			if err := enc.Encode(res); err != nil {
+				return internaldomain.WrapErrorf(err, internaldomain.ErrorCodeUnkown, "enc.Encode")
+			}
+		}
+	}
+
+	return nil
+}
+
+type output struct {
+	Position    int64      `json:"position,omitempty"`
+	Type        string     `json:"type,omitempty"`
+	OccurredAt  *time.Time `json:"occurred_at,omitempty"`
+	ID          string     `json:"id"`
+	Version     int64      `json:"version"`
+	Description string     `json:"description,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	IsDone      bool       `json:"is_done,omitempty"`
+}
+
+func newOutput(task internaldomain.Task) output {
+	res := output{
+		ID:          task.ID,
+		Version:     task.Version,
+		Description: task.Description,
+		IsDone:      task.IsDone,
+	}
+
+	if task.Description != "" {
+		res.Priority = task.Priority.String()
+	}
+
+	if !task.Dates.Start.IsZero() {
+		res.StartDate = &task.Dates.Start
+	}
+
+	if !task.Dates.Due.IsZero() {
+		res.DueDate = &task.Dates.Due
+	}
+
+	return res
+}
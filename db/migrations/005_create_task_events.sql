@@ -0,0 +1,26 @@
+CREATE TABLE task_events (
+  position       BIGINT GENERATED ALWAYS AS IDENTITY NOT NULL UNIQUE,
+  task_id        UUID NOT NULL,
+  version        BIGINT NOT NULL,
+  type           VARCHAR NOT NULL,
+  data           JSONB NOT NULL,
+  occurred_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  transaction_id BIGINT NOT NULL DEFAULT pg_current_xact_id()::text::bigint,
+  PRIMARY KEY (task_id, version)
+);
+
+CREATE INDEX task_events_transaction_id_position_idx ON task_events (transaction_id, position);
+
+CREATE TABLE task_snapshots (
+  task_id     UUID NOT NULL,
+  version     BIGINT NOT NULL,
+  data        JSONB NOT NULL,
+  occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  PRIMARY KEY (task_id, version)
+);
+
+---- create above / drop below ----
+
+DROP TABLE task_snapshots;
+
+DROP TABLE task_events;
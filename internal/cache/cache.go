@@ -0,0 +1,213 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+)
+
+const otelName = "github.com/sanLimbu/todo-api/internal/cache"
+
+// searchGenerationKey holds the generation included in the keys of cached search results, bumping it invalidates all
+// of them at once.
+const searchGenerationKey = "search_generation"
+
+var (
+	// ErrMiss is returned by Cache implementations when the key is not cached.
+	ErrMiss = internal.NewErrorf(internal.ErrorCodeUnkown, "cache miss")
+
+	// ErrNotStored is returned by Cache.Add when the key is already cached.
+	ErrNotStored = internal.NewErrorf(internal.ErrorCodeUnkown, "not stored")
+)
+
+// Cache defines the operations of the backends used by the decorators to store values. Keys don't contain whitespace
+// and are at most 250 bytes, a zero expiration means the value does not expire.
+type Cache interface {
+	// Get returns the cached value, it fails with ErrMiss when key is not cached.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set caches the value replacing the current one, if any.
+	Set(ctx context.Context, key string, value []byte, expiration time.Duration) error
+	// Add caches the value only when key is not cached yet, otherwise it fails with ErrNotStored.
+	Add(ctx context.Context, key string, value []byte, expiration time.Duration) error
+	// Increment adds delta to the cached decimal number and returns the new value, it fails with ErrMiss when key is
+	// not cached.
+	Increment(ctx context.Context, key string, delta uint64) (uint64, error)
+	// Delete removes the key, deleting keys that are not cached is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// errNotFound is returned when the key was cached as not found, negative entries are stored as empty values because
+// encoded values are never empty.
+var errNotFound = internal.NewErrorf(internal.ErrorCodeNotFound, "cached as not found")
+
+// requests counts cache lookups, the hit ratio is hits divided by all requests.
+var requests, _ = otel.Meter(otelName).Int64Counter("cache.requests",
+	metric.WithDescription("Number of cache lookups by result"))
+
+// getValue decodes the cached value into target, it fails with ErrMiss when key is not cached and with errNotFound
+// when it was cached using setNotFound. Values that can't be decoded, for example because they were cached by a
+// previous version, are deleted and reported as ErrMiss.
+func getValue(ctx context.Context, c Cache, key string, target interface{}) error {
+	defer newOTELSpan(ctx, "getValue").End()
+
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "c.Get")
+	}
+
+	if len(value) == 0 {
+		return errNotFound
+	}
+
+	if err := unmarshal(value, target); err != nil {
+		_ = c.Delete(ctx, key)
+
+		return internal.WrapErrorf(ErrMiss, internal.ErrorCodeUnkown, "unmarshal: %v", err)
+	}
+
+	return nil
+}
+
+func setValue(ctx context.Context, c Cache, key string, value interface{}, expiration time.Duration) {
+	defer newOTELSpan(ctx, "setValue").End()
+
+	b, err := marshal(value)
+	if err != nil {
+		return
+	}
+
+	_ = c.Set(ctx, key, b, expiration)
+}
+
+// setNotFound caches key as not found so repeated lookups of missing records don't reach the datastore.
+func setNotFound(ctx context.Context, c Cache, key string, expiration time.Duration) {
+	defer newOTELSpan(ctx, "setNotFound").End()
+
+	_ = c.Set(ctx, key, []byte{}, expiration)
+}
+
+func deleteValue(ctx context.Context, c Cache, key string) {
+	defer newOTELSpan(ctx, "deleteValue").End()
+
+	_ = c.Delete(ctx, key)
+}
+
+// searchGeneration returns the current search generation, initializing it when it is not cached.
+func searchGeneration(ctx context.Context, c Cache) (uint64, error) {
+	defer newOTELSpan(ctx, "searchGeneration").End()
+
+	value, err := c.Get(ctx, searchGenerationKey)
+	if err == nil {
+		gen, err := strconv.ParseUint(string(value), 10, 64)
+		if err != nil {
+			return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "strconv.ParseUint")
+		}
+
+		return gen, nil
+	}
+
+	if !errors.Is(err, ErrMiss) {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "c.Get")
+	}
+
+	return initSearchGeneration(ctx, c)
+}
+
+// bumpSearchGeneration increments the search generation so results cached before it are not used anymore.
+func bumpSearchGeneration(ctx context.Context, c Cache) error {
+	defer newOTELSpan(ctx, "bumpSearchGeneration").End()
+
+	_, err := c.Increment(ctx, searchGenerationKey, 1)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, ErrMiss) {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "c.Increment")
+	}
+
+	if _, err := initSearchGeneration(ctx, c); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "initSearchGeneration")
+	}
+
+	return nil
+}
+
+// initSearchGeneration stores the current time as the search generation, when the key was evicted the new generation
+// is still greater than the previous ones so stale results are never used again.
+func initSearchGeneration(ctx context.Context, c Cache) (uint64, error) {
+	gen := uint64(time.Now().UnixNano())
+
+	err := c.Add(ctx, searchGenerationKey, []byte(strconv.FormatUint(gen, 10)), 0)
+	if err == nil {
+		return gen, nil
+	}
+
+	if !errors.Is(err, ErrNotStored) {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "c.Add")
+	}
+
+	// Another client initialized it first, use theirs instead.
+	value, err := c.Get(ctx, searchGenerationKey)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "c.Get")
+	}
+
+	gen, err = strconv.ParseUint(string(value), 10, 64)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "strconv.ParseUint")
+	}
+
+	return gen, nil
+}
+
+// coalesce calls fn only once for concurrent calls using the same key, all of them receive the same result. fn
+// is not cancelled when the first caller's ctx is done because other callers may be waiting for it, instead every
+// caller stops waiting when its own ctx is done.
+func coalesce(ctx context.Context, group *singleflight.Group, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	resC := group.DoChan(key, func() (interface{}, error) {
+		return fn(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, internal.WrapErrorf(ctx.Err(), internal.ErrorCodeUnkown, "ctx.Done")
+	case res := <-resC:
+		return res.Val, res.Err
+	}
+}
+
+func recordRequest(ctx context.Context, cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+
+	requests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("cache", cache),
+		attribute.String("result", result)))
+}
+
+// errorCode returns the code of err so not found and invalid argument errors are kept when wrapping it.
+func errorCode(err error) internal.ErrorCode {
+	var ierr *internal.Error
+	if errors.As(err, &ierr) {
+		return ierr.Code()
+	}
+
+	return internal.ErrorCodeUnkown
+}
+
+func newOTELSpan(ctx context.Context, name string) trace.Span {
+	_, span := otel.Tracer(otelName).Start(ctx, name)
+
+	return span
+}
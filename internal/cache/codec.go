@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// codecPrefix precedes the JSON document of every cached value, it must be changed whenever the documents change in
+// an incompatible way so values cached by the previous version are discarded instead of decoded incorrectly.
+var codecPrefix = []byte("v1:")
+
+// cachedTask is the JSON document used for caching internal.Task, field names and priorities match the REST API so
+// the values are readable by other tools.
+type cachedTask struct {
+	ID          string       `json:"id"`
+	Description string       `json:"description"`
+	Priority    string       `json:"priority"`
+	StartDate   *time.Time   `json:"start_date,omitempty"`
+	DueDate     *time.Time   `json:"due_date,omitempty"`
+	SubTasks    []cachedTask `json:"sub_tasks,omitempty"`
+	Categories  []string     `json:"categories,omitempty"`
+	IsDone      bool         `json:"is_done"`
+	Version     int64        `json:"version"`
+}
+
+type cachedSearchResults struct {
+	Tasks      []cachedTask `json:"tasks"`
+	Total      int64        `json:"total"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// marshal encodes v as JSON prefixed with the codec version.
+func marshal(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Marshal")
+	}
+
+	return append(append([]byte{}, codecPrefix...), b...), nil
+}
+
+// unmarshal decodes the value encoded using marshal, values encoded by other versions fail with
+// ErrorCodeInvalidArgument.
+func unmarshal(data []byte, v interface{}) error {
+	if !bytes.HasPrefix(data, codecPrefix) {
+		return internal.NewErrorf(internal.ErrorCodeInvalidArgument, "unknown codec version")
+	}
+
+	if err := json.Unmarshal(data[len(codecPrefix):], v); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "json.Unmarshal")
+	}
+
+	return nil
+}
+
+func newCachedTask(task internal.Task) cachedTask {
+	res := cachedTask{
+		ID:          task.ID,
+		Description: task.Description,
+		Priority:    task.Priority.String(),
+		IsDone:      task.IsDone,
+		Version:     task.Version,
+	}
+
+	if !task.Dates.Start.IsZero() {
+		start := task.Dates.Start
+		res.StartDate = &start
+	}
+
+	if !task.Dates.Due.IsZero() {
+		due := task.Dates.Due
+		res.DueDate = &due
+	}
+
+	for _, sub := range task.SubTasks {
+		res.SubTasks = append(res.SubTasks, newCachedTask(sub))
+	}
+
+	for _, category := range task.Categories {
+		res.Categories = append(res.Categories, string(category))
+	}
+
+	return res
+}
+
+// Convert returns the domain type.
+func (t cachedTask) Convert() internal.Task {
+	// Values are written by newCachedTask, unknown ones can't be found.
+	priority, _ := internal.ParsePriority(t.Priority)
+
+	res := internal.Task{
+		ID:          t.ID,
+		Description: t.Description,
+		Priority:    priority,
+		IsDone:      t.IsDone,
+		Version:     t.Version,
+	}
+
+	if t.StartDate != nil {
+		res.Dates.Start = *t.StartDate
+	}
+
+	if t.DueDate != nil {
+		res.Dates.Due = *t.DueDate
+	}
+
+	for _, sub := range t.SubTasks {
+		res.SubTasks = append(res.SubTasks, sub.Convert())
+	}
+
+	for _, category := range t.Categories {
+		res.Categories = append(res.Categories, internal.Category(category))
+	}
+
+	return res
+}
+
+func newCachedSearchResults(results internal.SearchResults) cachedSearchResults {
+	res := cachedSearchResults{
+		Tasks:      make([]cachedTask, 0, len(results.Task)),
+		Total:      results.Total,
+		NextCursor: results.NextCursor,
+	}
+
+	for _, task := range results.Task {
+		res.Tasks = append(res.Tasks, newCachedTask(task))
+	}
+
+	return res
+}
+
+// Convert returns the domain type.
+func (r cachedSearchResults) Convert() internal.SearchResults {
+	res := internal.SearchResults{
+		Total:      r.Total,
+		NextCursor: r.NextCursor,
+	}
+
+	for _, task := range r.Tasks {
+		res.Task = append(res.Task, task.Convert())
+	}
+
+	return res
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// LRU represents the in-process cache, when it is full the least recently used value is evicted. Values are only
+// visible to the process caching them.
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRU instantiates the LRU cache holding up to size values.
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:  size,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Get returns the cached value.
+func (l *LRU) Get(_ context.Context, key string) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.entry(key)
+	if !ok {
+		return nil, ErrMiss
+	}
+
+	return append([]byte(nil), entry.value...), nil
+}
+
+// Set caches the value.
+func (l *LRU) Set(_ context.Context, key string, value []byte, expiration time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.set(key, value, newExpiresAt(expiration))
+
+	return nil
+}
+
+// Add caches the value when key is not cached.
+func (l *LRU) Add(_ context.Context, key string, value []byte, expiration time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.entry(key); ok {
+		return ErrNotStored
+	}
+
+	l.set(key, value, newExpiresAt(expiration))
+
+	return nil
+}
+
+// Increment adds delta to the cached number, the expiration is kept.
+func (l *LRU) Increment(_ context.Context, key string, delta uint64) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.entry(key)
+	if !ok {
+		return 0, ErrMiss
+	}
+
+	n, err := strconv.ParseUint(string(entry.value), 10, 64)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "strconv.ParseUint")
+	}
+
+	n += delta
+
+	l.set(key, []byte(strconv.FormatUint(n, 10)), entry.expiresAt)
+
+	return n, nil
+}
+
+// Delete removes the key.
+func (l *LRU) Delete(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[key]; ok {
+		l.remove(elem)
+	}
+
+	return nil
+}
+
+// entry returns the entry of key unless it expired, in that case it is removed. It must be called holding mu.
+func (l *LRU) entry(key string) (*lruEntry, bool) {
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+
+	if !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+		l.remove(elem)
+
+		return nil, false
+	}
+
+	l.order.MoveToFront(elem)
+
+	return entry, true
+}
+
+// set caches value evicting the least recently used values when full. It must be called holding mu.
+func (l *LRU) set(key string, value []byte, expiresAt time.Time) {
+	value = append([]byte(nil), value...)
+
+	if elem, ok := l.items[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+
+		l.order.MoveToFront(elem)
+
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&lruEntry{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	for l.order.Len() > l.size {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *LRU) remove(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.items, elem.Value.(*lruEntry).key)
+}
+
+func newExpiresAt(expiration time.Duration) time.Time {
+	if expiration == 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(expiration)
+}
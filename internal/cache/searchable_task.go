@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+type SearchableTask struct {
+	client Cache
+	orig   SearchableTaskStore
+	group  singleflight.Group
+	logger *zap.Logger
+}
+
+type SearchableTaskStore interface {
+	Delete(ctx context.Context, id string, version int64) error
+	Index(ctx context.Context, task internal.Task) error
+	Search(ctx context.Context, args internal.SearchParams) (internal.SearchResults, error)
+}
+
+// NewSearchableTask instantiates the Task repository
+func NewSearchableTask(client Cache, orig SearchableTaskStore, logger *zap.Logger) *SearchableTask {
+	return &SearchableTask{
+		client: client,
+		orig:   orig,
+		logger: logger,
+	}
+}
+
+// Index indexes the task and invalidates the cached search results.
+func (t *SearchableTask) Index(ctx context.Context, task internal.Task) error {
+	defer newOTELSpan(ctx, "SearchableTask.Index").End()
+
+	if err := t.orig.Index(ctx, task); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "orig.Index")
+	}
+
+	t.invalidate(ctx)
+
+	return nil
+}
+
+// Delete deletes the task from the index and invalidates the cached search results.
+func (t *SearchableTask) Delete(ctx context.Context, id string, version int64) error {
+	defer newOTELSpan(ctx, "SearchableTask.Delete").End()
+
+	if err := t.orig.Delete(ctx, id, version); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "orig.Delete")
+	}
+
+	t.invalidate(ctx)
+
+	return nil
+}
+
+// Search returns the cached results, when they are not cached the datastore is searched and the results cached.
+// Concurrent misses of the same search are coalesced into a single search.
+func (t *SearchableTask) Search(ctx context.Context, args internal.SearchParams) (internal.SearchResults, error) {
+	defer newOTELSpan(ctx, "SearchableTask.Search").End()
+
+	gen, err := searchGeneration(ctx, t.client)
+	if err != nil {
+		// Without the generation cached results may be stale, so the cache is not used at all.
+		t.logger.Warn("Couldn't get search generation", zap.Error(err))
+
+		recordRequest(ctx, "search", false)
+
+		res, err := t.orig.Search(ctx, args)
+		if err != nil {
+			return internal.SearchResults{}, internal.WrapErrorf(err, errorCode(err), "orig.Search")
+		}
+
+		return res, nil
+	}
+
+	key := newSearchableKey(gen, args)
+
+	var res cachedSearchResults
+
+	err = getValue(ctx, t.client, key, &res)
+	if err == nil {
+		recordRequest(ctx, "search", true)
+
+		return res.Convert(), nil
+	}
+
+	if !errors.Is(err, ErrMiss) {
+		t.logger.Warn("Couldn't get cached search results", zap.Error(err))
+	}
+
+	recordRequest(ctx, "search", false)
+
+	val, err := coalesce(ctx, &t.group, key, func(ctx context.Context) (interface{}, error) {
+		res, err := t.orig.Search(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+
+		setValue(ctx, t.client, key, newCachedSearchResults(res), 25*time.Second)
+
+		return res, nil
+	})
+	if err != nil {
+		return internal.SearchResults{}, internal.WrapErrorf(err, errorCode(err), "orig.Search")
+	}
+
+	return val.(internal.SearchResults), nil
+}
+
+func (t *SearchableTask) invalidate(ctx context.Context) {
+	if err := bumpSearchGeneration(ctx, t.client); err != nil {
+		t.logger.Warn("Couldn't invalidate cached search results", zap.Error(err))
+	}
+}
+
+// newSearchableKey hashes the arguments because keys can't include whitespace and are limited to 250 bytes, gen is
+// the search generation so results cached before the last write are not found. Arguments are encoded as JSON so
+// values not searched, null, are different from zero values and no value can be confused with the next one.
+func newSearchableKey(gen uint64, args internal.SearchParams) string {
+	// Encoding strings, integers and booleans never fails.
+	b, _ := json.Marshal(struct {
+		Description *string            `json:"description"`
+		Priority    *internal.Priority `json:"priority"`
+		IsDone      *bool              `json:"is_done"`
+		From        int64              `json:"from"`
+		Size        int64              `json:"size"`
+		Cursor      string             `json:"cursor"`
+	}{
+		Description: args.Description,
+		Priority:    args.Priority,
+		IsDone:      args.IsDone,
+		From:        args.From,
+		Size:        args.Size,
+		Cursor:      args.Cursor,
+	})
+
+	sum := sha256.Sum256(b)
+
+	return fmt.Sprintf("search_%d_%s", gen, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+type fakeSearchableTaskStore struct {
+	SearchableTaskStore
+
+	deleteErr error
+}
+
+func (f *fakeSearchableTaskStore) Delete(_ context.Context, _ string, _ int64) error {
+	return f.deleteErr
+}
+
+func TestNewSearchableKey(t *testing.T) {
+	t.Parallel()
+
+	empty, low, done, notDone := "", internal.PriorityNone, true, false
+
+	args := []internal.SearchParams{
+		{Size: 10},
+		{Description: &empty, Size: 10},
+		{Priority: &low, Size: 10},
+		{IsDone: &notDone, Size: 10},
+		{IsDone: &done, Size: 10},
+		{Description: &empty, Priority: &low, IsDone: &notDone, Size: 10},
+		{Description: &empty, Size: 10, Cursor: "a"},
+	}
+
+	keys := make(map[string]int)
+
+	for i, arg := range args {
+		key := newSearchableKey(1, arg)
+
+		if j, ok := keys[key]; ok {
+			t.Fatalf("arguments %d and %d have the same key", j, i)
+		}
+
+		keys[key] = i
+	}
+
+	if newSearchableKey(1, args[0]) == newSearchableKey(2, args[0]) {
+		t.Fatalf("expected generations to have different keys")
+	}
+}
+
+func TestSearchableTask_Delete(t *testing.T) {
+	t.Parallel()
+
+	orig := &fakeSearchableTaskStore{deleteErr: internal.NewErrorf(internal.ErrorCodeInvalidArgument, "invalid")}
+
+	err := NewSearchableTask(NewLRU(10), orig, zap.NewNop()).Delete(context.Background(), "id", 1)
+
+	var ierr *internal.Error
+	if !errors.As(err, &ierr) || ierr.Code() != internal.ErrorCodeUnkown {
+		t.Fatalf("expected unknown error, got %v", err)
+	}
+
+	if !errors.As(ierr.Unwrap(), &ierr) || ierr.Code() != internal.ErrorCodeInvalidArgument {
+		t.Fatalf("expected the original error code to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// Task represents the cache-aside decorator of the repository used for interacting with Task records, concurrent
+// misses of the same task are coalesced into a single call to the datastore.
+type Task struct {
+	client             Cache
+	orig               TaskStore
+	expiration         time.Duration
+	negativeExpiration time.Duration
+	group              singleflight.Group
+	logger             *zap.Logger
+}
+
+type TaskStore interface {
+	Create(ctx context.Context, params internal.CreateParams) (internal.Task, error)
+	Delete(ctx context.Context, id string) (int64, error)
+	Find(ctx context.Context, id string) (internal.Task, error)
+	Update(ctx context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) (internal.Task, error)
+}
+
+func NewTask(client Cache, orig TaskStore, logger *zap.Logger) *Task {
+	return &Task{
+		client:             client,
+		orig:               orig,
+		expiration:         15 * time.Minute,
+		negativeExpiration: time.Minute,
+		logger:             logger,
+	}
+}
+
+func (t *Task) Create(ctx context.Context, params internal.CreateParams) (internal.Task, error) {
+	defer newOTELSpan(ctx, "Task.Create").End()
+
+	task, err := t.orig.Create(ctx, params)
+	if err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, errorCode(err), "orig.Create")
+	}
+
+	setValue(ctx, t.client, task.ID, newCachedTask(task), t.expiration)
+	t.invalidateSearch(ctx)
+
+	return task, nil
+}
+
+// Delete deletes the task and caches it as not found, cached search results are invalidated.
+func (t *Task) Delete(ctx context.Context, id string) (int64, error) {
+	defer newOTELSpan(ctx, "Task.Delete").End()
+
+	version, err := t.orig.Delete(ctx, id)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, errorCode(err), "orig.Delete")
+	}
+
+	setNotFound(ctx, t.client, id, t.negativeExpiration)
+	t.invalidateSearch(ctx)
+
+	return version, nil
+}
+
+// Find returns the cached task, when it is not cached it is read from the datastore and cached; tasks that are not
+// found are cached for a shorter time. Errors reading from the cache are ignored so the datastore is used instead.
+func (t *Task) Find(ctx context.Context, id string) (internal.Task, error) {
+	defer newOTELSpan(ctx, "Task.Find").End()
+
+	var res cachedTask
+
+	err := getValue(ctx, t.client, id, &res)
+	switch {
+	case err == nil:
+		recordRequest(ctx, "task", true)
+
+		return res.Convert(), nil
+	case errors.Is(err, errNotFound):
+		recordRequest(ctx, "task", true)
+
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeNotFound, "task not found")
+	case !errors.Is(err, ErrMiss):
+		t.logger.Warn("Couldn't get cached task", zap.Error(err))
+	}
+
+	recordRequest(ctx, "task", false)
+
+	val, err := coalesce(ctx, &t.group, id, func(ctx context.Context) (interface{}, error) {
+		res, err := t.orig.Find(ctx, id)
+		if err != nil {
+			if errorCode(err) == internal.ErrorCodeNotFound {
+				setNotFound(ctx, t.client, id, t.negativeExpiration)
+			}
+
+			return nil, err
+		}
+
+		setValue(ctx, t.client, res.ID, newCachedTask(res), t.expiration)
+
+		return res, nil
+	})
+	if err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, errorCode(err), "orig.Find")
+	}
+
+	return val.(internal.Task), nil
+}
+
+// Update updates the task and caches the updated one, cached search results are invalidated. Finds in flight may
+// have read the previous task, so they are not shared with later ones.
+func (t *Task) Update(ctx context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) (internal.Task, error) {
+	defer newOTELSpan(ctx, "Task.Update").End()
+
+	task, err := t.orig.Update(ctx, id, description, priority, dates, isDone)
+	if err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, errorCode(err), "orig.Update")
+	}
+
+	t.group.Forget(id)
+
+	setValue(ctx, t.client, id, newCachedTask(task), t.expiration)
+	t.invalidateSearch(ctx)
+
+	return task, nil
+}
+
+// invalidateSearch bumps the search generation, searching PostgreSQL sees the write right away while Elasticsearch
+// results are invalidated again by the indexer once the task is indexed.
+func (t *Task) invalidateSearch(ctx context.Context) {
+	if err := bumpSearchGeneration(ctx, t.client); err != nil {
+		t.logger.Warn("Couldn't invalidate cached search results", zap.Error(err))
+	}
+}
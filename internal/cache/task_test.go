@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// fakeTaskStore returns the first version of the task until it is updated, finding it blocks until release is
+// closed.
+type fakeTaskStore struct {
+	TaskStore
+
+	finding chan struct{}
+	release chan struct{}
+}
+
+func (f *fakeTaskStore) Find(_ context.Context, id string) (internal.Task, error) {
+	close(f.finding)
+	<-f.release
+
+	return internal.Task{ID: id, Description: "old", Version: 1}, nil
+}
+
+func (f *fakeTaskStore) Update(_ context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) (internal.Task, error) {
+	return internal.Task{ID: id, Description: description, Priority: priority, Dates: dates, IsDone: isDone, Version: 2}, nil
+}
+
+func TestTask_UpdateDuringFind(t *testing.T) {
+	t.Parallel()
+
+	orig := &fakeTaskStore{finding: make(chan struct{}), release: make(chan struct{})}
+	repo := NewTask(NewLRU(10), orig, zap.NewNop())
+
+	ctx := context.Background()
+
+	stale := make(chan internal.Task)
+
+	go func() {
+		task, _ := repo.Find(ctx, "task-1")
+		stale <- task
+	}()
+
+	<-orig.finding
+
+	updated, err := repo.Update(ctx, "task-1", "new", internal.PriorityHigh, internal.Dates{}, false)
+	if err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+
+	if updated.Version != 2 || updated.Description != "new" {
+		t.Fatalf("expected the updated task, got %+v", updated)
+	}
+
+	// Finding the task after updating it must not wait for the Find started before.
+	found, err := repo.Find(ctx, "task-1")
+	if err != nil {
+		t.Fatalf("Find failed: %s", err)
+	}
+
+	if found.Version != 2 {
+		t.Fatalf("expected the updated task, got version %d", found.Version)
+	}
+
+	close(orig.release)
+
+	if task := <-stale; task.Version != 1 {
+		t.Fatalf("expected the Find in flight to return the previous task, got version %d", task.Version)
+	}
+}
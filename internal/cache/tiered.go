@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// Tiered represents the two-tier cache, values are read from the local cache first and from the shared cache when
+// they are missing locally. Writes go to the shared cache and remove the local copy, local copies of values changed by
+// other processes are used for at most ttl. The search generation is only cached in the shared cache, that way
+// search results cached by other processes are invalidated as soon as they bump it.
+type Tiered struct {
+	local  Cache
+	shared Cache
+	ttl    time.Duration
+}
+
+// NewTiered instantiates the Tiered cache, usually local is an LRU and shared is memcached or Redis.
+func NewTiered(local, shared Cache, ttl time.Duration) *Tiered {
+	return &Tiered{
+		local:  local,
+		shared: shared,
+		ttl:    ttl,
+	}
+}
+
+// Get returns the local value, when missing it returns the shared value and caches it locally.
+func (t *Tiered) Get(ctx context.Context, key string) ([]byte, error) {
+	if !sharedOnly(key) {
+		if value, err := t.local.Get(ctx, key); err == nil {
+			return value, nil
+		}
+	}
+
+	value, err := t.shared.Get(ctx, key)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "shared.Get")
+	}
+
+	if !sharedOnly(key) {
+		_ = t.local.Set(ctx, key, value, t.ttl)
+	}
+
+	return value, nil
+}
+
+// Set caches the value in both tiers.
+func (t *Tiered) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	if err := t.shared.Set(ctx, key, value, expiration); err != nil {
+		_ = t.local.Delete(ctx, key)
+
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "shared.Set")
+	}
+
+	if !sharedOnly(key) {
+		_ = t.local.Set(ctx, key, value, t.localExpiration(expiration))
+	}
+
+	return nil
+}
+
+// Add caches the value in the shared cache when key is not cached there.
+func (t *Tiered) Add(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	_ = t.local.Delete(ctx, key)
+
+	if err := t.shared.Add(ctx, key, value, expiration); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "shared.Add")
+	}
+
+	return nil
+}
+
+// Increment increments the shared value.
+func (t *Tiered) Increment(ctx context.Context, key string, delta uint64) (uint64, error) {
+	_ = t.local.Delete(ctx, key)
+
+	n, err := t.shared.Increment(ctx, key, delta)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "shared.Increment")
+	}
+
+	return n, nil
+}
+
+// Delete removes the key from both tiers.
+func (t *Tiered) Delete(ctx context.Context, key string) error {
+	_ = t.local.Delete(ctx, key)
+
+	if err := t.shared.Delete(ctx, key); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "shared.Delete")
+	}
+
+	return nil
+}
+
+func (t *Tiered) localExpiration(expiration time.Duration) time.Duration {
+	if expiration == 0 || expiration > t.ttl {
+		return t.ttl
+	}
+
+	return expiration
+}
+
+// sharedOnly returns whether the key is never cached locally.
+func sharedOnly(key string) bool {
+	return key == searchGenerationKey
+}
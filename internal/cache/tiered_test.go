@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTiered_SearchGeneration(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// Two processes sharing the same cache.
+	shared := NewLRU(10)
+	first := NewTiered(NewLRU(10), shared, time.Minute)
+	second := NewTiered(NewLRU(10), shared, time.Minute)
+
+	// The first call initializes the generation, the second one reads it.
+	if _, err := searchGeneration(ctx, first); err != nil {
+		t.Fatalf("searchGeneration: %s", err)
+	}
+
+	gen, err := searchGeneration(ctx, first)
+	if err != nil {
+		t.Fatalf("searchGeneration: %s", err)
+	}
+
+	if err := bumpSearchGeneration(ctx, second); err != nil {
+		t.Fatalf("bumpSearchGeneration: %s", err)
+	}
+
+	bumped, err := searchGeneration(ctx, first)
+	if err != nil {
+		t.Fatalf("searchGeneration: %s", err)
+	}
+
+	if bumped != gen+1 {
+		t.Fatalf("expected generation %d after being bumped by another process, got %d", gen+1, bumped)
+	}
+}
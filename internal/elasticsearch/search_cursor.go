@@ -0,0 +1,171 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+
+	esv7api "github.com/elastic/go-elasticsearch/v7/esapi"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+const (
+	// pointInTimeKeepAlive indicates how long a point in time is kept between requests for the next page.
+	pointInTimeKeepAlive = "1m"
+
+	// maxResultWindow is the default "index.max_result_window", the maximum value of from + size.
+	maxResultWindow = 10000
+)
+
+// searchCursor represents the opaque value returned to clients for requesting the next page of results.
+//
+// The first page is searched without a point in time, so cursors returned with it don't include one. The point in
+// time is opened when the next page is requested, so the following pages are consistent with it, and "search_after"
+// paginates past "max_result_window". Cursors can only be used with the same search arguments.
+type searchCursor struct {
+	PointInTime string        `json:"pit,omitempty"`
+	SearchAfter []interface{} `json:"after"`
+	Offset      int64         `json:"offset"` // number of results preceding the next page.
+	Query       string        `json:"query"`  // hash of the search arguments, see newQueryHash.
+}
+
+// Encode returns the opaque representation of the cursor.
+func (c searchCursor) Encode() (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Marshal")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeSearchCursor returns the cursor, it must have been created for the search arguments hashed as query.
+func decodeSearchCursor(v, query string) (searchCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return searchCursor{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "base64.DecodeString")
+	}
+
+	var res searchCursor
+
+	if err := json.Unmarshal(b, &res); err != nil {
+		return searchCursor{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "json.Unmarshal")
+	}
+
+	if len(res.SearchAfter) == 0 || res.Offset < 0 {
+		return searchCursor{}, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "invalid cursor")
+	}
+
+	if res.Query != query {
+		return searchCursor{}, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "cursor belongs to a different search")
+	}
+
+	return res, nil
+}
+
+// newQueryHash returns the hash identifying the values searched, size is not included so pages can have
+// different sizes.
+func newQueryHash(args internal.SearchParams) (string, error) {
+	b, err := json.Marshal(struct {
+		Description *string            `json:"description"`
+		Priority    *internal.Priority `json:"priority"`
+		IsDone      *bool              `json:"is_done"`
+	}{
+		Description: args.Description,
+		Priority:    args.Priority,
+		IsDone:      args.IsDone,
+	})
+	if err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Marshal")
+	}
+
+	sum := sha256.Sum256(b)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
+
+// newSearchSort returns the sort of the results, ties are broken using the id. The "tasks" index is mapped
+// dynamically, so ids are text fields whose "keyword" subfield is sortable; "unmapped_type" allows searching an index
+// without tasks yet. Searches using a point in time sort by its tiebreaker as well, so search_after values always
+// include it.
+func newSearchSort(pointInTime bool) []interface{} {
+	res := []interface{}{
+		"_score",
+		map[string]interface{}{
+			"id.keyword": map[string]interface{}{
+				"order":         "asc",
+				"unmapped_type": "keyword",
+			},
+		},
+	}
+
+	if pointInTime {
+		res = append(res, map[string]interface{}{"_shard_doc": "asc"})
+	}
+
+	return res
+}
+
+func (t *Task) openPointInTime(ctx context.Context) (string, error) {
+	defer newOTELSpan(ctx, "Task.openPointInTime").End()
+
+	req := esv7api.OpenPointInTimeRequest{
+		Index:     []string{t.index},
+		KeepAlive: pointInTimeKeepAlive,
+	}
+
+	resp, err := req.Do(ctx, t.client)
+	if err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "OpenPointInTimeRequest.Do")
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return "", internal.NewErrorf(internal.ErrorCodeUnkown, "OpenPointInTimeRequest.Do %d", resp.StatusCode)
+	}
+
+	var res struct {
+		ID string `json:"id"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.NewDecoder.Decode")
+	}
+
+	return res.ID, nil
+}
+
+// closePointInTime releases the point in time, errors are ignored because Elasticsearch eventually expires it.
+func (t *Task) closePointInTime(ctx context.Context, id string) {
+	defer newOTELSpan(ctx, "Task.closePointInTime").End()
+
+	var buf bytes.Buffer
+
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"id": id}); err != nil {
+		return
+	}
+
+	req := esv7api.ClosePointInTimeRequest{
+		Body: &buf,
+	}
+
+	resp, err := req.Do(ctx, t.client)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+}
+
+// closeOpenedPointInTime releases the point in time opened by the failed search, the client never receives a cursor
+// using it.
+func (t *Task) closeOpenedPointInTime(ctx context.Context, opened bool, cursor searchCursor) {
+	if opened {
+		t.closePointInTime(ctx, cursor.PointInTime)
+	}
+}
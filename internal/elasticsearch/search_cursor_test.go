@@ -0,0 +1,249 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	esv7 "github.com/elastic/go-elasticsearch/v7"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// fakeSearch serves the point in time and search APIs, the index holds total tasks whose ids are their position.
+type fakeSearch struct {
+	mu       sync.Mutex
+	total    int
+	opened   int
+	closed   int
+	paths    []string
+	requests []map[string]interface{}
+}
+
+func (f *fakeSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/":
+		// The client checks it is connected to Elasticsearch before the first request.
+		fmt.Fprint(w, `{"version":{"number":"7.17.9","build_flavor":"default"},"tagline":"You Know, for Search"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/tasks/_pit":
+		f.opened++
+
+		fmt.Fprintf(w, `{"id":"pit-%d"}`, f.opened)
+	case r.Method == http.MethodDelete && r.URL.Path == "/_pit":
+		f.closed++
+
+		fmt.Fprint(w, `{"succeeded":true}`)
+	case r.URL.Path == "/_search" || r.URL.Path == "/tasks/_search":
+		var body map[string]interface{}
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		f.paths = append(f.paths, r.URL.Path)
+		f.requests = append(f.requests, body)
+
+		from := 0
+		if v, ok := body["from"].(float64); ok {
+			from = int(v)
+		}
+
+		if after, ok := body["search_after"].([]interface{}); ok {
+			id, _ := strconv.Atoi(after[1].(string))
+			from = id + 1
+		}
+
+		pit, _ := body["pit"].(map[string]interface{})
+
+		type hit struct {
+			Source indexedTask   `json:"_source"`
+			Sort   []interface{} `json:"sort"`
+		}
+
+		hits := []hit{}
+
+		for i := from; i < f.total && len(hits) < int(body["size"].(float64)); i++ {
+			sort := []interface{}{1, fmt.Sprint(i)}
+			if pit != nil {
+				sort = append(sort, i) // _shard_doc
+			}
+
+			hits = append(hits, hit{Source: indexedTask{ID: fmt.Sprint(i)}, Sort: sort})
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"pit_id": pit["id"],
+			"hits": map[string]interface{}{
+				"total": map[string]interface{}{"value": f.total, "relation": "eq"},
+				"hits":  hits,
+			},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newFakeTask(t *testing.T, total int) (*Task, *fakeSearch) {
+	t.Helper()
+
+	fake := &fakeSearch{total: total}
+
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client, err := esv7.NewClient(esv7.Config{Addresses: []string{srv.URL}})
+	if err != nil {
+		t.Fatalf("esv7.NewClient: %s", err)
+	}
+
+	return NewTask(client), fake
+}
+
+func TestTask_SearchCursor(t *testing.T) {
+	t.Parallel()
+
+	description := "milk"
+
+	t.Run("Pages", func(t *testing.T) {
+		t.Parallel()
+
+		repo, fake := newFakeTask(t, 5)
+
+		args := internal.SearchParams{Description: &description, Size: 2}
+
+		var ids []string
+
+		for i := 0; ; i++ {
+			if i > 3 {
+				t.Fatalf("expected pagination to end")
+			}
+
+			res, err := repo.Search(context.Background(), args)
+			if err != nil {
+				t.Fatalf("Search failed: %s", err)
+			}
+
+			for _, task := range res.Task {
+				ids = append(ids, task.ID)
+			}
+
+			if res.NextCursor == "" {
+				break
+			}
+
+			args.Cursor = res.NextCursor
+		}
+
+		if fmt.Sprint(ids) != "[0 1 2 3 4]" {
+			t.Fatalf("unexpected tasks %v", ids)
+		}
+
+		if fake.opened != 1 || fake.closed != 1 {
+			t.Fatalf("expected the point in time to be opened and closed once, got %d and %d", fake.opened, fake.closed)
+		}
+
+		if _, ok := fake.requests[0]["pit"]; ok || fake.paths[0] != "/tasks/_search" {
+			t.Fatalf("expected the first page to search the index without point in time")
+		}
+
+		// The point in time is opened for the second page, which continues after the last task of the first one.
+		if _, ok := fake.requests[1]["pit"]; !ok || fake.paths[1] != "/_search" {
+			t.Fatalf("expected the second page to use the point in time")
+		}
+
+		after := fake.requests[1]["search_after"].([]interface{})
+		if len(after) != 3 || after[1] != "1" || after[2].(float64) != math.MaxInt64 {
+			t.Fatalf("unexpected search_after %v", after)
+		}
+
+		if after := fake.requests[2]["search_after"].([]interface{}); len(after) != 3 || after[1] != "3" || after[2].(float64) != 3 {
+			t.Fatalf("unexpected search_after %v", after)
+		}
+	})
+
+	t.Run("First page", func(t *testing.T) {
+		t.Parallel()
+
+		repo, fake := newFakeTask(t, 5)
+
+		res, err := repo.Search(context.Background(), internal.SearchParams{Description: &description, Size: 2})
+		if err != nil {
+			t.Fatalf("Search failed: %s", err)
+		}
+
+		if len(res.Task) != 2 || res.NextCursor == "" {
+			t.Fatalf("expected 2 tasks and a cursor, got %d and %q", len(res.Task), res.NextCursor)
+		}
+
+		if fake.opened != 0 {
+			t.Fatalf("expected no point in time to be opened")
+		}
+	})
+
+	t.Run("Last page is full", func(t *testing.T) {
+		t.Parallel()
+
+		repo, fake := newFakeTask(t, 4)
+
+		res, err := repo.Search(context.Background(), internal.SearchParams{Description: &description, From: 2, Size: 2})
+		if err != nil {
+			t.Fatalf("Search failed: %s", err)
+		}
+
+		if len(res.Task) != 2 || res.NextCursor != "" {
+			t.Fatalf("expected 2 tasks and no cursor, got %d and %q", len(res.Task), res.NextCursor)
+		}
+
+		if fake.opened != 0 || fake.closed != 0 {
+			t.Fatalf("expected no point in time to be used")
+		}
+	})
+
+	t.Run("Different search", func(t *testing.T) {
+		t.Parallel()
+
+		repo, _ := newFakeTask(t, 5)
+
+		res, err := repo.Search(context.Background(), internal.SearchParams{Description: &description, Size: 2})
+		if err != nil {
+			t.Fatalf("Search failed: %s", err)
+		}
+
+		other := "bread"
+
+		_, err = repo.Search(context.Background(), internal.SearchParams{Description: &other, Size: 2, Cursor: res.NextCursor})
+
+		var ierr *internal.Error
+		if !errors.As(err, &ierr) || ierr.Code() != internal.ErrorCodeInvalidArgument {
+			t.Fatalf("expected invalid argument error, got %v", err)
+		}
+	})
+
+	t.Run("Past the result window", func(t *testing.T) {
+		t.Parallel()
+
+		repo, fake := newFakeTask(t, 5)
+
+		_, err := repo.Search(context.Background(), internal.SearchParams{Description: &description, From: maxResultWindow, Size: 1})
+
+		var ierr *internal.Error
+		if !errors.As(err, &ierr) || ierr.Code() != internal.ErrorCodeInvalidArgument {
+			t.Fatalf("expected invalid argument error, got %v", err)
+		}
+
+		if fake.opened != 0 {
+			t.Fatalf("expected no point in time to be opened")
+		}
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"math"
+	"net/http"
 	"time"
 
 	esv7 "github.com/elastic/go-elasticsearch/v7"
@@ -40,7 +42,8 @@ func NewTask(client *esv7.Client) *Task {
 	}
 }
 
-//Index creates or updates a task in an index.
+//Index creates or updates a task in an index. Tasks with a version use external versioning, so indexing a version
+//older than or equal to the indexed one, or than a recently deleted one, is ignored; see Delete.
 func (t *Task) Index(ctx context.Context, task internal.Task) error {
 
 	defer newOTELSpan(ctx, "Task.Index").End()
@@ -48,6 +51,7 @@ func (t *Task) Index(ctx context.Context, task internal.Task) error {
 	body := indexedTask{
 		ID:          task.ID,
 		Description: task.Description,
+		Priority:    task.Priority,
 		IsDone:      task.IsDone,
 		DateStart:   task.Dates.Start.UnixNano(),
 		DateDue:     task.Dates.Due.UnixNano(),
@@ -67,37 +71,68 @@ func (t *Task) Index(ctx context.Context, task internal.Task) error {
 		Refresh:    "true",
 	}
 
+	if task.Version > 0 {
+		version := int(task.Version)
+
+		req.Version = &version
+		req.VersionType = "external"
+	}
+
 	resp, err := req.Do(ctx, t.client)
 	if err != nil {
 		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "IndexRequest.Do")
 	}
 	defer resp.Body.Close()
+
+	// A conflict means a newer version was indexed or the task was deleted, this one is stale.
+	if resp.StatusCode == http.StatusConflict {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
 	if resp.IsError() {
-		return internal.NewErrorf(internal.ErrorCodeUnkown, "IndexRequest.Do %s", resp.StatusCode)
+		return internal.NewErrorf(internal.ErrorCodeUnkown, "IndexRequest.Do %d", resp.StatusCode)
 	}
 	io.Copy(io.Discard, resp.Body)
 	return nil
 
 }
 
-//Delete removes a task from the index
-func (t *Task) Delete(ctx context.Context, id string) error {
+//Delete removes a task from the index, deleting a task that is not indexed is not an error. Deletions with a version
+//use external versioning like Index: deleting a version older than the indexed one is ignored, and the tombstone
+//left behind makes indexing older versions fail. Tombstones only last for the index.gc_deletes setting, 60 seconds
+//by default, so events delayed longer than that may index a deleted task again.
+func (t *Task) Delete(ctx context.Context, id string, version int64) error {
 
 	defer newOTELSpan(ctx, "Task.Delete").End()
 
 	req := esv7api.DeleteRequest{
 		Index:      t.index,
 		DocumentID: id,
+		Refresh:    "true",
+	}
+
+	if version > 0 {
+		v := int(version)
+
+		req.Version = &v
+		req.VersionType = "external"
 	}
 
 	resp, err := req.Do(ctx, t.client)
 	if err != nil {
-		return internal.WrapErrorf(err, internal.ErrorCodeNotFound, "DeleteRequest.Do")
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "DeleteRequest.Do")
 	}
 	defer resp.Body.Close()
 
-	if resp.IsError() {
-		return internal.NewErrorf(internal.ErrorCodeUnkown, "DeleteRequest.Do %s", resp.StatusCode)
+	// A conflict means a newer version was indexed, this deletion is stale.
+	if resp.StatusCode == http.StatusConflict {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	if resp.IsError() && resp.StatusCode != http.StatusNotFound {
+		return internal.NewErrorf(internal.ErrorCodeUnkown, "DeleteRequest.Do %d", resp.StatusCode)
 	}
 
 	io.Copy(io.Discard, resp.Body)
@@ -156,13 +191,61 @@ func (t *Task) Search(ctx context.Context, args internal.SearchParams) (internal
 		}
 	}
 
-	query["sort"] = []interface{}{
-		"_score",
-		map[string]interface{}{"id": "asc"},
+	queryHash, err := newQueryHash(args)
+	if err != nil {
+		return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newQueryHash")
 	}
 
-	query["from"] = args.From
+	var (
+		cursor searchCursor
+		opened bool // the point in time was opened by this request, so it is closed when searching fails.
+	)
+
+	if args.Cursor != "" {
+		cursor, err = decodeSearchCursor(args.Cursor, queryHash)
+		if err != nil {
+			return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "decodeSearchCursor")
+		}
+
+		// The point in time is opened once the client asks for the next page, the first one is searched without it.
+		if cursor.PointInTime == "" {
+			cursor.PointInTime, err = t.openPointInTime(ctx)
+			if err != nil {
+				return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "openPointInTime")
+			}
+
+			opened = true
+
+			// The first page is not sorted by the point in time tiebreaker, the highest value continues right after
+			// its last task because ids are unique.
+			cursor.SearchAfter = append(cursor.SearchAfter, int64(math.MaxInt64))
+		}
+
+		query["search_after"] = cursor.SearchAfter
+	} else {
+		// Pages past the window are requested using the cursor.
+		if args.From+args.Size > maxResultWindow {
+			return internal.SearchResults{}, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "from + size must not be greater than %d", maxResultWindow)
+		}
+
+		cursor = searchCursor{
+			Offset: args.From,
+			Query:  queryHash,
+		}
+
+		query["from"] = args.From
+	}
+
+	query["sort"] = newSearchSort(cursor.PointInTime != "")
 	query["size"] = args.Size
+	query["track_total_hits"] = true
+
+	if cursor.PointInTime != "" {
+		query["pit"] = map[string]interface{}{
+			"id":         cursor.PointInTime,
+			"keep_alive": pointInTimeKeepAlive,
+		}
+	}
 
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(query); err != nil {
@@ -170,27 +253,40 @@ func (t *Task) Search(ctx context.Context, args internal.SearchParams) (internal
 	}
 
 	req := esv7api.SearchRequest{
-		Index: []string{t.index},
-		Body:  &buf,
+		Body: &buf,
+	}
+
+	// Requests using a point in time must not define the index, it is implicit in the point in time.
+	if cursor.PointInTime == "" {
+		req.Index = []string{t.index}
 	}
 
 	resp, err := req.Do(ctx, t.client)
 	if err != nil {
+		t.closeOpenedPointInTime(ctx, opened, cursor)
 		return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "SearchRequest.Do")
 	}
 	defer resp.Body.Close()
 
+	// The point in time expired, the client must search the first page again.
+	if resp.StatusCode == http.StatusNotFound && args.Cursor != "" && !opened {
+		return internal.SearchResults{}, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "cursor expired")
+	}
+
 	if resp.IsError() {
+		t.closeOpenedPointInTime(ctx, opened, cursor)
 		return internal.SearchResults{}, internal.NewErrorf(internal.ErrorCodeUnkown, "SearchRequest.Do %d", resp.StatusCode)
 	}
 
 	var hits struct {
-		Hits struct {
+		PointInTime string `json:"pit_id"`
+		Hits        struct {
 			Total struct {
 				Value int64 `json:"value"`
 			} `json:"total"`
 			Hits []struct {
-				Source indexedTask `json:"_source"`
+				Source indexedTask   `json:"_source"`
+				Sort   []interface{} `json:"sort"`
 			} `json:"hits"`
 		} `json:"hits"`
 	}
@@ -203,12 +299,36 @@ func (t *Task) Search(ctx context.Context, args internal.SearchParams) (internal
 		res[i].ID = hit.Source.ID
 		res[i].Description = hit.Source.Description
 		res[i].Priority = internal.Priority(hit.Source.Priority)
+		res[i].IsDone = hit.Source.IsDone
 		res[i].Dates.Due = time.Unix(0, hit.Source.DateDue).UTC()
 		res[i].Dates.Start = time.Unix(0, hit.Source.DateStart).UTC()
 	}
+
+	// Elasticsearch may return a new point in time id, subsequent requests must always use the latest one.
+	if hits.PointInTime != "" {
+		cursor.PointInTime = hits.PointInTime
+	}
+
+	var nextCursor string
+
+	// Like the other repositories, there is a next page only when this one is full and more results remain.
+	if count := int64(len(hits.Hits.Hits)); count > 0 && count == args.Size && cursor.Offset+count < hits.Hits.Total.Value {
+		cursor.SearchAfter = hits.Hits.Hits[count-1].Sort
+		cursor.Offset += count
+
+		nextCursor, err = cursor.Encode()
+		if err != nil {
+			return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "cursor.Encode")
+		}
+	} else if cursor.PointInTime != "" {
+		// No more results, the point in time is not needed anymore.
+		t.closePointInTime(ctx, cursor.PointInTime)
+	}
+
 	return internal.SearchResults{
-		Task:  res,
-		Total: hits.Hits.Total.Value,
+		Task:       res,
+		Total:      hits.Hits.Total.Value,
+		NextCursor: nextCursor,
 	}, nil
 
 }
@@ -0,0 +1,66 @@
+package elasticsearch
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	esv7 "github.com/elastic/go-elasticsearch/v7"
+	esv7api "github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/google/uuid"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/service/servicetesting"
+)
+
+func TestTask(t *testing.T) {
+	url := os.Getenv("ELASTICSEARCH_TEST_URL")
+	if url == "" {
+		t.Skip("ELASTICSEARCH_TEST_URL is not set")
+	}
+
+	client, err := esv7.NewClient(esv7.Config{Addresses: []string{url}})
+	if err != nil {
+		t.Fatalf("esv7.NewClient: %s", err)
+	}
+
+	servicetesting.TaskSearchRepository(t, func(t *testing.T) servicetesting.SearchFixture {
+		repo := NewTask(client)
+
+		// Every fixture uses its own index, so only the tasks stored by the contract are searchable. The index is
+		// mapped dynamically like the one used by the servers.
+		repo.index = "tasks-test-" + uuid.NewString()
+
+		resp, err := esv7api.IndicesCreateRequest{
+			Index: repo.index,
+		}.Do(context.Background(), client)
+		if err != nil {
+			t.Fatalf("IndicesCreateRequest.Do: %s", err)
+		}
+
+		resp.Body.Close()
+
+		if resp.IsError() {
+			t.Fatalf("IndicesCreateRequest.Do: %d", resp.StatusCode)
+		}
+
+		t.Cleanup(func() {
+			resp, err := esv7api.IndicesDeleteRequest{Index: []string{repo.index}}.Do(context.Background(), client)
+			if err == nil {
+				resp.Body.Close()
+			}
+		})
+
+		return servicetesting.SearchFixture{
+			Search: repo,
+			Store: func(ctx context.Context, task internal.Task) (internal.Task, error) {
+				task.ID = uuid.NewString()
+
+				return task, repo.Index(ctx, task)
+			},
+			Remove: func(ctx context.Context, id string) error {
+				return repo.Delete(ctx, id, 0)
+			},
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package awssm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// getTimeout is how long getting a secret waits for Secrets Manager to respond.
+const getTimeout = 10 * time.Second
+
+// Provider reads secrets from AWS Secrets Manager or any service implementing its API, like LocalStack.
+type Provider struct {
+	client  *secretsmanager.Client
+	refresh time.Duration
+	logger  *zap.Logger
+
+	mu      sync.Mutex
+	results map[string]secret
+}
+
+type secret struct {
+	value  string
+	readAt time.Time
+}
+
+// New instantiates the Provider using the default AWS credentials chain, endpoint replaces the one resolved for the
+// region when it is not empty. Secrets are cached for refresh before reading them again, zero caches them forever.
+func New(region, endpoint string, refresh time.Duration, logger *zap.Logger) (*Provider, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), getTimeout)
+	defer cancel()
+
+	var opts []func(*config.LoadOptions) error
+
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "config.LoadDefaultConfig")
+	}
+
+	client := secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+
+	return &Provider{
+		client:  client,
+		refresh: refresh,
+		logger:  logger,
+		results: make(map[string]secret),
+	}, nil
+}
+
+// Get returns the value of a secret, the secret ID is its name or ARN. Secrets storing JSON objects, like the ones
+// created by the console, select the key after "#", for example "prod/database#password" retrieves the key
+// "password" of the secret "prod/database". When reading a secret again fails the cached value is used.
+func (p *Provider) Get(v string) (string, error) {
+	id, key, hasKey := strings.Cut(v, "#")
+	if id == "" {
+		return "", internal.NewErrorf(internal.ErrorCodeInvalidArgument, "missing secret ID")
+	}
+
+	secret, err := p.secret(id)
+	if err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "secret")
+	}
+
+	if !hasKey {
+		return secret, nil
+	}
+
+	var data map[string]interface{}
+
+	if err := json.Unmarshal([]byte(secret), &data); err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "json.Unmarshal")
+	}
+
+	val, ok := data[key]
+	if !ok {
+		return "", internal.NewErrorf(internal.ErrorCodeNotFound, "key not found in secret")
+	}
+
+	res, ok := val.(string)
+	if !ok {
+		return "", internal.NewErrorf(internal.ErrorCodeUnkown, "secret value in data is not string")
+	}
+
+	return res, nil
+}
+
+func (p *Provider) secret(id string) (string, error) {
+	p.mu.Lock()
+	res, ok := p.results[id]
+	p.mu.Unlock()
+
+	if ok && (p.refresh == 0 || time.Since(res.readAt) < p.refresh) {
+		return res.value, nil
+	}
+
+	val, err := p.read(id)
+	switch {
+	case err == nil:
+		res = secret{value: val, readAt: time.Now()}
+
+		p.mu.Lock()
+		p.results[id] = res
+		p.mu.Unlock()
+	case ok:
+		p.logger.Warn("Couldn't read secret again, using cached value", zap.String("id", id), zap.Error(err))
+	default:
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "read")
+	}
+
+	return res.value, nil
+}
+
+func (p *Provider) read(id string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), getTimeout)
+	defer cancel()
+
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(id),
+	})
+	if err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "GetSecretValue")
+	}
+
+	switch {
+	case out.SecretString != nil:
+		return *out.SecretString, nil
+	case out.SecretBinary != nil:
+		return string(out.SecretBinary), nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,261 @@
+package awssm_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/envar/awssm"
+)
+
+// server is a stub of the Secrets Manager API, it counts the requests for each secret and blocks the ones for
+// secrets in block until the channel is closed.
+type server struct {
+	mu       sync.Mutex
+	secrets  map[string]string
+	requests map[string]int
+	block    map[string]chan struct{}
+}
+
+func newServer(t *testing.T, secrets map[string]string) (*server, string) {
+	t.Helper()
+
+	srv := &server{secrets: secrets, requests: make(map[string]int), block: make(map[string]chan struct{})}
+
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(httpSrv.Close)
+
+	return srv, httpSrv.URL
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+	if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"InvalidRequestException","message":"unexpected operation"}`))
+
+		return
+	}
+
+	var req struct {
+		SecretID string `json:"SecretId"`
+	}
+
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	s.mu.Lock()
+	s.requests[req.SecretID]++
+	block := s.block[req.SecretID]
+	s.mu.Unlock()
+
+	if block != nil {
+		<-block
+	}
+
+	s.mu.Lock()
+	val, ok := s.secrets[req.SecretID]
+	s.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"Name": req.SecretID, "SecretString": val})
+}
+
+func (s *server) set(id, val string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.secrets[id] = val
+}
+
+func (s *server) delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.secrets, id)
+}
+
+func (s *server) count(id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[id]
+}
+
+func newProvider(t *testing.T, endpoint string, refresh time.Duration) *awssm.Provider {
+	t.Helper()
+
+	// The credentials are only used for signing the requests.
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	provider, err := awssm.New("us-east-1", endpoint, refresh, zap.NewNop())
+	if err != nil {
+		t.Fatalf("awssm.New: %s", err)
+	}
+
+	return provider
+}
+
+func TestProvider_Get(t *testing.T) {
+	_, endpoint := newServer(t, map[string]string{
+		"prod/api-key":  "abc123",
+		"prod/database": `{"username":"todo","password":"s3cr3t","port":5432}`,
+	})
+
+	provider := newProvider(t, endpoint, 0)
+
+	for v, want := range map[string]string{
+		"prod/api-key":           "abc123",
+		"prod/database":          `{"username":"todo","password":"s3cr3t","port":5432}`,
+		"prod/database#username": "todo",
+		"prod/database#password": "s3cr3t",
+	} {
+		got, err := provider.Get(v)
+		if err != nil {
+			t.Fatalf("Get(%s): %s", v, err)
+		}
+
+		if got != want {
+			t.Fatalf("Get(%s): expected %q, got %q", v, want, got)
+		}
+	}
+}
+
+func TestProvider_GetFailed(t *testing.T) {
+	_, endpoint := newServer(t, map[string]string{
+		"prod/api-key":  "abc123",
+		"prod/database": `{"username":"todo","port":5432}`,
+	})
+
+	provider := newProvider(t, endpoint, 0)
+
+	tests := []struct {
+		name string
+		v    string
+		code internal.ErrorCode
+	}{
+		{"Missing ID", "#password", internal.ErrorCodeInvalidArgument},
+		{"Secret not found", "prod/missing", internal.ErrorCodeUnkown},
+		{"Secret is not JSON", "prod/api-key#password", internal.ErrorCodeInvalidArgument},
+		{"Key not found", "prod/database#password", internal.ErrorCodeNotFound},
+		{"Value is not string", "prod/database#port", internal.ErrorCodeUnkown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.Get(tt.v)
+
+			var ierr *internal.Error
+			if !errors.As(err, &ierr) || ierr.Code() != tt.code {
+				t.Fatalf("expected error code %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestProvider_GetCached(t *testing.T) {
+	srv, endpoint := newServer(t, map[string]string{"prod/database": `{"password":"s3cr3t"}`})
+
+	provider := newProvider(t, endpoint, 0)
+
+	for _, v := range []string{"prod/database#password", "prod/database", "prod/database#password"} {
+		if _, err := provider.Get(v); err != nil {
+			t.Fatalf("Get(%s): %s", v, err)
+		}
+	}
+
+	if n := srv.count("prod/database"); n != 1 {
+		t.Fatalf("expected the secret to be read once, got %d requests", n)
+	}
+}
+
+func TestProvider_GetRefreshed(t *testing.T) {
+	srv, endpoint := newServer(t, map[string]string{"prod/database": `{"password":"s3cr3t"}`})
+
+	provider := newProvider(t, endpoint, 50*time.Millisecond)
+
+	get := func() string {
+		val, err := provider.Get("prod/database#password")
+		if err != nil {
+			t.Fatalf("Get: %s", err)
+		}
+
+		return val
+	}
+
+	if val := get(); val != "s3cr3t" {
+		t.Fatalf("unexpected value %q", val)
+	}
+
+	srv.set("prod/database", `{"password":"rotated"}`)
+
+	if val := get(); val != "s3cr3t" {
+		t.Fatalf("expected the cached value, got %q", val)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if val := get(); val != "rotated" {
+		t.Fatalf("expected the rotated value, got %q", val)
+	}
+
+	// Failing to read the secret again keeps using the cached value.
+	srv.delete("prod/database")
+
+	time.Sleep(100 * time.Millisecond)
+
+	if val := get(); val != "rotated" {
+		t.Fatalf("expected the cached value, got %q", val)
+	}
+
+	if n := srv.count("prod/database"); n != 3 {
+		t.Fatalf("expected the secret to be read 3 times, got %d requests", n)
+	}
+}
+
+func TestProvider_GetConcurrent(t *testing.T) {
+	srv, endpoint := newServer(t, map[string]string{"prod/slow": "slow", "prod/fast": "fast"})
+
+	unblock := make(chan struct{})
+	srv.block["prod/slow"] = unblock
+
+	provider := newProvider(t, endpoint, 0)
+
+	slow := make(chan error)
+
+	go func() {
+		_, err := provider.Get("prod/slow")
+		slow <- err
+	}()
+
+	for srv.count("prod/slow") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Reading a secret does not block reading other secrets.
+	if val, err := provider.Get("prod/fast"); err != nil || val != "fast" {
+		t.Fatalf("Get: unexpected value %q, error %v", val, err)
+	}
+
+	close(unblock)
+
+	if err := <-slow; err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+}
@@ -0,0 +1,62 @@
+package envvar
+
+import (
+	"strings"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// Chain is a Provider that selects the provider to use by the scheme of the value, for example
+// "file:///run/secrets/password" uses the provider registered as "file" for getting "/run/secrets/password". Values
+// without scheme use the default provider, that way values defined before schemes were supported keep working.
+type Chain struct {
+	providers     map[string]Provider
+	defaultScheme string
+}
+
+// NewChain instantiates the Chain, values without scheme use the provider registered as defaultScheme.
+func NewChain(defaultScheme string) *Chain {
+	return &Chain{
+		providers:     make(map[string]Provider),
+		defaultScheme: defaultScheme,
+	}
+}
+
+// Register uses provider for getting the values using scheme, it replaces the provider previously registered, if any.
+func (c *Chain) Register(scheme string, provider Provider) {
+	c.providers[scheme] = provider
+}
+
+// Provider returns the provider registered as scheme, it is nil when there's none.
+func (c *Chain) Provider(scheme string) Provider {
+	return c.providers[scheme]
+}
+
+// Get returns the value using the provider selected by the scheme of v.
+func (c *Chain) Get(v string) (string, error) {
+	scheme, key, ok := strings.Cut(v, "://")
+	if !ok {
+		scheme, key = c.defaultScheme, v
+	}
+
+	provider, ok := c.providers[scheme]
+	if !ok {
+		return "", internal.NewErrorf(internal.ErrorCodeInvalidArgument, "%q provider is not configured", scheme)
+	}
+
+	val, err := provider.Get(key)
+	if err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "%s.Get", scheme)
+	}
+
+	return val, nil
+}
+
+// Close closes the registered providers that need to be closed, like the Vault one.
+func (c *Chain) Close() {
+	for _, provider := range c.providers {
+		if closer, ok := provider.(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package envvar_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sanLimbu/todo-api/internal"
+	envvar "github.com/sanLimbu/todo-api/internal/envar"
+	"github.com/sanLimbu/todo-api/internal/envar/envvartesting"
+)
+
+type closer struct {
+	envvartesting.FakeProvider
+
+	closed bool
+}
+
+func (c *closer) Close() {
+	c.closed = true
+}
+
+func TestChain_Get(t *testing.T) {
+	t.Parallel()
+
+	vault := &envvartesting.FakeProvider{}
+	vault.GetReturns("from vault", nil)
+
+	file := &envvartesting.FakeProvider{}
+	file.GetReturns("from file", nil)
+
+	chain := envvar.NewChain("vault")
+	chain.Register("vault", vault)
+	chain.Register("file", file)
+
+	tests := []struct {
+		v        string
+		provider *envvartesting.FakeProvider
+		key      string
+		want     string
+	}{
+		{"/database:password", vault, "/database:password", "from vault"},
+		{"vault:///database:password", vault, "/database:password", "from vault"},
+		{"file:///run/secrets/password", file, "/run/secrets/password", "from file"},
+		{"file://password", file, "password", "from file"},
+	}
+
+	for _, tt := range tests {
+		calls := tt.provider.GetCallCount()
+
+		got, err := chain.Get(tt.v)
+		if err != nil {
+			t.Fatalf("Get(%s): %s", tt.v, err)
+		}
+
+		if got != tt.want {
+			t.Fatalf("Get(%s): expected %q, got %q", tt.v, tt.want, got)
+		}
+
+		if tt.provider.GetCallCount() != calls+1 {
+			t.Fatalf("Get(%s): expected the provider to be called", tt.v)
+		}
+
+		if key := tt.provider.GetArgsForCall(calls); key != tt.key {
+			t.Fatalf("Get(%s): expected key %q, got %q", tt.v, tt.key, key)
+		}
+	}
+}
+
+func TestChain_GetFailed(t *testing.T) {
+	t.Parallel()
+
+	file := &envvartesting.FakeProvider{}
+	file.GetReturns("", internal.NewErrorf(internal.ErrorCodeNotFound, "not found"))
+
+	// The default provider is not registered, like Vault when VAULT_ADDRESS is not set.
+	chain := envvar.NewChain("vault")
+	chain.Register("file", file)
+
+	tests := []struct {
+		name string
+		v    string
+		code internal.ErrorCode
+	}{
+		{"Unknown scheme", "sops://secrets.enc.yaml#password", internal.ErrorCodeInvalidArgument},
+		{"Default provider not registered", "/database:password", internal.ErrorCodeInvalidArgument},
+		{"Provider failed", "file://password", internal.ErrorCodeUnkown},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := chain.Get(tt.v)
+
+			var ierr *internal.Error
+			if !errors.As(err, &ierr) || ierr.Code() != tt.code {
+				t.Fatalf("expected error code %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestChain_Close(t *testing.T) {
+	t.Parallel()
+
+	vault := &closer{}
+
+	chain := envvar.NewChain("vault")
+	chain.Register("vault", vault)
+	chain.Register("file", &envvartesting.FakeProvider{})
+
+	if chain.Provider("vault") != vault || chain.Provider("sops") != nil {
+		t.Fatalf("unexpected registered providers")
+	}
+
+	chain.Close()
+
+	if !vault.closed {
+		t.Fatalf("expected the provider to be closed")
+	}
+}
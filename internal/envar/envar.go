@@ -36,6 +36,11 @@ func New(provider Provider) *Configuration {
 	}
 }
 
+// Provider returns the provider used for getting the values of `<key>_SECURE` variables, it may be nil.
+func (c *Configuration) Provider() Provider {
+	return c.provider
+}
+
 // Get returns the value from environment variable `<key>`. When an environment variable `<key>_SECURE` exists
 // the provider is used for getting the value.
 func (c *Configuration) Get(key string) (string, error) {
@@ -43,6 +48,10 @@ func (c *Configuration) Get(key string) (string, error) {
 	valSecret := os.Getenv(key + "_SECURE")
 
 	if valSecret != "" {
+		if c.provider == nil {
+			return "", internal.NewErrorf(internal.ErrorCodeInvalidArgument, "%s_SECURE is not supported", key)
+		}
+
 		valSecretRes, err := c.provider.Get(valSecret)
 		if err != nil {
 			return "", internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "provider.Get")
@@ -0,0 +1,45 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// Provider reads secrets from files, like the ones mounted by Docker or Kubernetes, each file contains one secret.
+type Provider struct {
+	dir string
+}
+
+// New instantiates the Provider, relative paths are resolved using dir.
+func New(dir string) *Provider {
+	return &Provider{
+		dir: dir,
+	}
+}
+
+// Get returns the content of the file at path v without the trailing newline, for example "db_password" reads
+// "/run/secrets/db_password" when using "/run/secrets" as directory.
+func (p *Provider) Get(v string) (string, error) {
+	if v == "" {
+		return "", internal.NewErrorf(internal.ErrorCodeInvalidArgument, "missing path")
+	}
+
+	path := v
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", internal.WrapErrorf(err, internal.ErrorCodeNotFound, "os.ReadFile")
+		}
+
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "os.ReadFile")
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
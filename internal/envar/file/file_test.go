@@ -0,0 +1,77 @@
+package file_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/envar/file"
+)
+
+func TestProvider_Get(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"db_password": "s3cr3t\n",
+		"api_key":     "abc123\r\n",
+		"multiline":   "line 1\nline 2\n\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile: %s", err)
+		}
+	}
+
+	provider := file.New(dir)
+
+	for v, want := range map[string]string{
+		"db_password":                     "s3cr3t",
+		"api_key":                         "abc123",
+		"multiline":                       "line 1\nline 2",
+		filepath.Join(dir, "db_password"): "s3cr3t",
+		filepath.Join("..", filepath.Base(dir), "api_key"): "abc123",
+	} {
+		got, err := provider.Get(v)
+		if err != nil {
+			t.Fatalf("Get(%s): %s", v, err)
+		}
+
+		if got != want {
+			t.Fatalf("Get(%s): expected %q, got %q", v, want, got)
+		}
+	}
+}
+
+func TestProvider_GetFailed(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		v    string
+		code internal.ErrorCode
+	}{
+		{"Missing path", "", internal.ErrorCodeInvalidArgument},
+		{"File not found", "missing", internal.ErrorCodeNotFound},
+		{"Directory", ".", internal.ErrorCodeUnkown},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := file.New(dir).Get(tt.v)
+
+			var ierr *internal.Error
+			if !errors.As(err, &ierr) || ierr.Code() != tt.code {
+				t.Fatalf("expected error code %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
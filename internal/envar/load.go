@@ -0,0 +1,253 @@
+package envvar
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// redacted replaces the values of secret settings when printing them.
+const redacted = "******"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load sets the fields of the struct pointed to by v using the environment variable named in their `env` tag, nested
+// structs without tag are loaded as well. Supported fields are string, bool, int, float64, time.Duration and []string,
+// values of the latter are separated by commas. The following tags are supported as well:
+//
+//   - `default`: value used when the environment variable is empty.
+//   - `required:"true"`: the value can't be empty.
+//   - `oneof`: space separated list of allowed values.
+//
+// All the invalid values are reported at once, fields that are valid are set even when others are not.
+func (c *Configuration) Load(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return internal.NewErrorf(internal.ErrorCodeInvalidArgument, "expected pointer to struct, got %T", v)
+	}
+
+	var errs []error
+
+	c.load(rv.Elem(), &errs)
+
+	if len(errs) > 0 {
+		return internal.WrapErrorf(errors.Join(errs...), internal.ErrorCodeInvalidArgument, "invalid configuration")
+	}
+
+	return nil
+}
+
+func (c *Configuration) load(rv reflect.Value, errs *[]error) {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, ok := field.Tag.Lookup("env")
+		if !ok {
+			if field.Type.Kind() == reflect.Struct {
+				c.load(rv.Field(i), errs)
+			}
+
+			continue
+		}
+
+		val, err := c.Get(key)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", key, err))
+
+			continue
+		}
+
+		val = strings.TrimSpace(val)
+		if val == "" {
+			val = field.Tag.Get("default")
+		}
+
+		if val == "" {
+			if field.Tag.Get("required") == "true" {
+				*errs = append(*errs, fmt.Errorf("%s is required", key))
+			}
+
+			continue
+		}
+
+		if oneof := field.Tag.Get("oneof"); oneof != "" && !isOneOf(field.Type, val, strings.Fields(oneof)) {
+			*errs = append(*errs, fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(strings.Fields(oneof), ", "), val))
+
+			continue
+		}
+
+		if err := setField(rv.Field(i), val); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+}
+
+func isOneOf(typ reflect.Type, val string, allowed []string) bool {
+	vals := []string{val}
+	if typ.Kind() == reflect.Slice {
+		vals = splitList(val)
+	}
+
+	for _, v := range vals {
+		found := false
+
+		for _, a := range allowed {
+			if v == a {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func setField(field reflect.Value, val string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", val)
+		}
+
+		field.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", val)
+		}
+
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", val)
+		}
+
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", val)
+		}
+
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+
+		field.Set(reflect.ValueOf(splitList(val)))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
+
+func splitList(val string) []string {
+	var res []string
+
+	for _, v := range strings.Split(val, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+// Print writes the settings of the structs pointed to by v in the env file format, fields tagged `secret:"true"` are
+// redacted. Values are the ones set by Load.
+func Print(w io.Writer, v ...interface{}) error {
+	for _, s := range v {
+		rv := reflect.ValueOf(s)
+		if rv.Kind() == reflect.Pointer {
+			rv = rv.Elem()
+		}
+
+		if rv.Kind() != reflect.Struct {
+			return internal.NewErrorf(internal.ErrorCodeInvalidArgument, "expected struct, got %T", s)
+		}
+
+		if err := printStruct(w, rv); err != nil {
+			return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "printStruct")
+		}
+	}
+
+	return nil
+}
+
+func printStruct(w io.Writer, rv reflect.Value) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, ok := field.Tag.Lookup("env")
+		if !ok {
+			if field.Type.Kind() == reflect.Struct {
+				if err := printStruct(w, rv.Field(i)); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		val := formatField(rv.Field(i))
+		if val != "" && field.Tag.Get("secret") == "true" {
+			val = redacted
+		}
+
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key, strconv.Quote(val)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatField(field reflect.Value) string {
+	if field.Type() == durationType {
+		return time.Duration(field.Int()).String()
+	}
+
+	switch field.Kind() {
+	case reflect.Slice:
+		vals := make([]string, 0, field.Len())
+
+		for i := 0; i < field.Len(); i++ {
+			vals = append(vals, fmt.Sprint(field.Index(i).Interface()))
+		}
+
+		return strings.Join(vals, ",")
+	default:
+		return fmt.Sprint(field.Interface())
+	}
+}
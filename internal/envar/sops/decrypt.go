@@ -0,0 +1,269 @@
+package sops
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// metadataKey is the top level key holding the SOPS metadata, it is not part of the values.
+const metadataKey = "sops"
+
+// encryptedValue matches the values encrypted by SOPS: ENC[AES256_GCM,data:<base64>,iv:<base64>,tag:<base64>,type:<type>].
+var encryptedValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// macOnlyEncryptedInitialization is hashed first when only encrypted values are part of the MAC, that way it differs
+// from the MAC of all the values.
+var macOnlyEncryptedInitialization = []byte{0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3,
+	0xd1, 0x47, 0xbe, 0xb, 0xb, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69}
+
+type metadata struct {
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	} `yaml:"age"`
+	LastModified     string `yaml:"lastmodified"`
+	MAC              string `yaml:"mac"`
+	MACOnlyEncrypted bool   `yaml:"mac_only_encrypted"`
+}
+
+// decrypt returns the values of the file indexed by their keys separated by dots, the MAC is verified so values that
+// were modified, added or removed without SOPS are detected.
+func decrypt(b []byte, identities []age.Identity) (map[string]string, error) {
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "yaml.Unmarshal")
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "expected map")
+	}
+
+	root := doc.Content[0]
+
+	var meta *metadata
+
+	for i := 0; i < len(root.Content); i += 2 {
+		if root.Content[i].Value == metadataKey {
+			meta = &metadata{}
+
+			if err := root.Content[i+1].Decode(meta); err != nil {
+				return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "Decode")
+			}
+		}
+	}
+
+	if meta == nil {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "file is not encrypted by SOPS")
+	}
+
+	key, err := dataKey(meta, identities)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "dataKey")
+	}
+
+	w := walker{
+		key:              key,
+		macOnlyEncrypted: meta.MACOnlyEncrypted,
+		hash:             sha512.New(),
+		values:           make(map[string]string),
+	}
+
+	if meta.MACOnlyEncrypted {
+		w.hash.Write(macOnlyEncryptedInitialization)
+	}
+
+	for i := 0; i < len(root.Content); i += 2 {
+		if name := root.Content[i].Value; name != metadataKey {
+			if err := w.walk(root.Content[i+1], []string{name}, []string{name}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	mac, _, err := decryptValue(meta.MAC, key, meta.LastModified)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "decrypting MAC")
+	}
+
+	if mac != fmt.Sprintf("%X", w.hash.Sum(nil)) {
+		return nil, internal.NewErrorf(internal.ErrorCodeUnkown, "MAC mismatch, the file was modified without SOPS")
+	}
+
+	return w.values, nil
+}
+
+// dataKey decrypts the key used for encrypting the values using the first age recipient matching the identities.
+func dataKey(meta *metadata, identities []age.Identity) ([]byte, error) {
+	if len(meta.Age) == 0 {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "file is not encrypted using age")
+	}
+
+	var err error
+
+	for _, recipient := range meta.Age {
+		var r io.Reader
+
+		if r, err = age.Decrypt(armor.NewReader(strings.NewReader(recipient.Enc)), identities...); err != nil {
+			continue
+		}
+
+		var key []byte
+
+		if key, err = io.ReadAll(r); err == nil {
+			return key, nil
+		}
+	}
+
+	return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "age.Decrypt")
+}
+
+type walker struct {
+	key              []byte
+	macOnlyEncrypted bool
+	hash             hash.Hash
+	values           map[string]string
+}
+
+// walk decrypts the values in the order they are defined, which is the order used for computing the MAC. path
+// is the additional data used by SOPS, it does not include list indexes, keys does.
+func (w *walker) walk(node *yaml.Node, path, keys []string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			name := node.Content[i].Value
+
+			if err := w.walk(node.Content[i+1], append(path, name), append(keys, name)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if err := w.walk(item, path, append(keys, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case yaml.AliasNode:
+		return w.walk(node.Alias, path, keys)
+	case yaml.ScalarNode:
+		// Null values are neither encrypted nor part of the MAC.
+		if node.ShortTag() == "!!null" {
+			return nil
+		}
+
+		val, typ := node.Value, scalarType(node)
+
+		encrypted := encryptedValue.MatchString(val)
+		if encrypted {
+			var err error
+
+			if val, typ, err = decryptValue(val, w.key, strings.Join(path, ":")+":"); err != nil {
+				return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "decrypting %s", strings.Join(keys, "."))
+			}
+		}
+
+		if encrypted || !w.macOnlyEncrypted {
+			w.hash.Write(macBytes(val, typ))
+		}
+
+		// Booleans are encrypted as "True" and "False", they are returned the way they are written in the file.
+		if b, err := strconv.ParseBool(val); err == nil && typ == "bool" {
+			val = strconv.FormatBool(b)
+		}
+
+		w.values[strings.Join(keys, ".")] = val
+	}
+
+	return nil
+}
+
+// decryptValue returns the plaintext and type of value, additionalData is the path of the value for preventing values
+// from being moved around.
+func decryptValue(value string, key []byte, additionalData string) (string, string, error) {
+	match := encryptedValue.FindStringSubmatch(value)
+	if match == nil {
+		return "", "", internal.NewErrorf(internal.ErrorCodeInvalidArgument, "invalid encrypted value")
+	}
+
+	var parts [3][]byte
+
+	for i := range parts {
+		b, err := base64.StdEncoding.DecodeString(match[i+1])
+		if err != nil {
+			return "", "", internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "base64.DecodeString")
+		}
+
+		parts[i] = b
+	}
+
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "aes.NewCipher")
+	}
+
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "cipher.NewGCMWithNonceSize")
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return "", "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "gcm.Open")
+	}
+
+	return string(plaintext), match[4], nil
+}
+
+// scalarType returns the SOPS type of values that are not encrypted.
+func scalarType(node *yaml.Node) string {
+	switch node.ShortTag() {
+	case "!!int":
+		return "int"
+	case "!!float":
+		return "float"
+	case "!!bool":
+		return "bool"
+	default:
+		return "str"
+	}
+}
+
+// macBytes returns the bytes of the value hashed by SOPS for computing the MAC, they depend on its type.
+func macBytes(val, typ string) []byte {
+	switch typ {
+	case "bool":
+		if b, err := strconv.ParseBool(val); err == nil {
+			if b {
+				return []byte("True")
+			}
+
+			return []byte("False")
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return []byte(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+	case "int":
+		if n, err := strconv.Atoi(val); err == nil {
+			return []byte(strconv.Itoa(n))
+		}
+	}
+
+	return []byte(val)
+}
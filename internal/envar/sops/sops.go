@@ -0,0 +1,80 @@
+package sops
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// Provider reads secrets from files encrypted by SOPS using age, YAML and JSON files are supported. Files are
+// decrypted once and kept in memory.
+type Provider struct {
+	identities []age.Identity
+
+	mu    sync.Mutex
+	files map[string]map[string]string
+}
+
+// New instantiates the Provider, keys contains the age identities used for decrypting the data key of the files, in
+// the format used by SOPS_AGE_KEY_FILE.
+func New(keys io.Reader) (*Provider, error) {
+	identities, err := age.ParseIdentities(keys)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "age.ParseIdentities")
+	}
+
+	return &Provider{
+		identities: identities,
+		files:      make(map[string]map[string]string),
+	}, nil
+}
+
+// Get returns the value of a key in an encrypted file, the key is separated from the path of the file by "#" and
+// nested keys are separated by dots. For example "secrets.enc.yaml#database.password" retrieves the key "password"
+// of the map "database" in "secrets.enc.yaml", list items are selected using their index.
+func (p *Provider) Get(v string) (string, error) {
+	path, key, ok := strings.Cut(v, "#")
+	if !ok || path == "" || key == "" {
+		return "", internal.NewErrorf(internal.ErrorCodeInvalidArgument, "expected <path>#<key>")
+	}
+
+	values, err := p.file(path)
+	if err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "file")
+	}
+
+	val, ok := values[key]
+	if !ok {
+		return "", internal.NewErrorf(internal.ErrorCodeNotFound, "key not found in file")
+	}
+
+	return val, nil
+}
+
+func (p *Provider) file(path string) (map[string]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if values, ok := p.files[path]; ok {
+		return values, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "os.ReadFile")
+	}
+
+	values, err := decrypt(b, p.identities)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "decrypt")
+	}
+
+	p.files[path] = values
+
+	return values, nil
+}
@@ -0,0 +1,289 @@
+package sops_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/envar/sops"
+)
+
+// The files in testdata were encrypted by the sops CLI using the age key in testdata/key.txt, which is only used by
+// these tests.
+
+func newProvider(t *testing.T) *sops.Provider {
+	t.Helper()
+
+	keys, err := os.Open(filepath.Join("testdata", "key.txt"))
+	if err != nil {
+		t.Fatalf("os.Open: %s", err)
+	}
+	t.Cleanup(func() { _ = keys.Close() })
+
+	provider, err := sops.New(keys)
+	if err != nil {
+		t.Fatalf("sops.New: %s", err)
+	}
+
+	return provider
+}
+
+// tamper writes a copy of the testdata file with old replaced by new.
+func tamper(t *testing.T, name, old, new string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("os.ReadFile: %s", err)
+	}
+
+	if !strings.Contains(string(b), old) {
+		t.Fatalf("%q not found in %s", old, name)
+	}
+
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(strings.Replace(string(b), old, new, 1)), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %s", err)
+	}
+
+	return path
+}
+
+// line returns the line of the testdata file defining key.
+func line(t *testing.T, name, key string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("os.ReadFile: %s", err)
+	}
+
+	for _, l := range strings.SplitAfter(string(b), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(l), key+":") {
+			return l
+		}
+	}
+
+	t.Fatalf("%s not found in %s", key, name)
+
+	return ""
+}
+
+func encryptedValue(t *testing.T, name, key string) string {
+	t.Helper()
+
+	_, val, _ := strings.Cut(line(t, name, key), ": ")
+
+	return strings.TrimSpace(val)
+}
+
+func TestProvider_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		file   string
+		values map[string]string
+	}{
+		{
+			"YAML",
+			"secrets.enc.yaml",
+			map[string]string{
+				"database.username":   "todo",
+				"database.password":   "s3cr3t",
+				"database.port":       "5432",
+				"database.ratio":      "0.5",
+				"database.tls":        "true",
+				"database.replicas.0": "replica-1.example.com",
+				"database.replicas.1": "replica-2.example.com",
+				"api_key":             "abc123",
+				"comment_unencrypted": "kept in plaintext",
+			},
+		},
+		{
+			"YAML MAC only encrypted",
+			"secrets_mac_only_encrypted.enc.yaml",
+			map[string]string{
+				"database.username":   "todo",
+				"database.port":       "5432",
+				"database.ratio":      "0.5",
+				"database.tls":        "true",
+				"database.replicas.1": "replica-2.example.com",
+				"api_key":             "abc123",
+				"comment_unencrypted": "kept in plaintext",
+			},
+		},
+		{
+			"JSON",
+			"secrets.enc.json",
+			map[string]string{
+				"database.password": "s3cr3t",
+				"database.port":     "5432",
+				"tokens.0":          "a",
+				"tokens.1":          "b",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := newProvider(t)
+
+			for key, want := range tt.values {
+				got, err := provider.Get(filepath.Join("testdata", tt.file) + "#" + key)
+				if err != nil {
+					t.Fatalf("Get(%s): %s", key, err)
+				}
+
+				if got != want {
+					t.Fatalf("Get(%s): expected %q, got %q", key, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestProvider_GetNotFound(t *testing.T) {
+	t.Parallel()
+
+	_, err := newProvider(t).Get(filepath.Join("testdata", "secrets.enc.yaml") + "#database.options")
+
+	var ierr *internal.Error
+	if !errors.As(err, &ierr) || ierr.Code() != internal.ErrorCodeNotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestProvider_GetInvalidArgument(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range []string{"", "secrets.enc.yaml", "#api_key", "secrets.enc.yaml#"} {
+		_, err := newProvider(t).Get(v)
+
+		var ierr *internal.Error
+		if !errors.As(err, &ierr) || ierr.Code() != internal.ErrorCodeInvalidArgument {
+			t.Fatalf("Get(%q): expected invalid argument error, got %v", v, err)
+		}
+	}
+}
+
+func TestProvider_GetTampered(t *testing.T) {
+	t.Parallel()
+
+	const (
+		file        = "secrets.enc.yaml"
+		macOnlyFile = "secrets_mac_only_encrypted.enc.yaml"
+	)
+
+	tests := []struct {
+		name string
+		path func(t *testing.T) string
+		err  string
+	}{
+		{
+			"Unencrypted value modified",
+			func(t *testing.T) string {
+				return tamper(t, file, "kept in plaintext", "modified in plaintext")
+			},
+			"MAC mismatch",
+		},
+		{
+			"Encrypted value removed",
+			func(t *testing.T) string {
+				return tamper(t, file, line(t, file, "api_key"), "")
+			},
+			"MAC mismatch",
+		},
+		{
+			"Unencrypted value added",
+			func(t *testing.T) string {
+				return tamper(t, file, "comment_unencrypted:", "added_unencrypted: value\ncomment_unencrypted:")
+			},
+			"MAC mismatch",
+		},
+		{
+			"Encrypted values swapped",
+			func(t *testing.T) string {
+				// The path of the value is authenticated, so moving it to another key fails to decrypt it.
+				return tamper(t, file, encryptedValue(t, file, "password"), encryptedValue(t, file, "username"))
+			},
+			"gcm.Open",
+		},
+		{
+			"MAC replaced",
+			func(t *testing.T) string {
+				// The MAC is authenticated using the last modified date.
+				return tamper(t, file, encryptedValue(t, file, "mac"), encryptedValue(t, file, "api_key"))
+			},
+			"decrypting MAC",
+		},
+		{
+			"MAC only encrypted value removed",
+			func(t *testing.T) string {
+				return tamper(t, macOnlyFile, line(t, macOnlyFile, "api_key"), "")
+			},
+			"MAC mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newProvider(t).Get(tt.path(t) + "#database.username")
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected %q error, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestProvider_GetMACOnlyEncryptedUnencryptedModified(t *testing.T) {
+	t.Parallel()
+
+	// Values that are not encrypted are not part of the MAC when only encrypted values are.
+	path := tamper(t, "secrets_mac_only_encrypted.enc.yaml", "kept in plaintext", "modified in plaintext")
+
+	got, err := newProvider(t).Get(path + "#comment_unencrypted")
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+
+	if got != "modified in plaintext" {
+		t.Fatalf("expected the modified value, got %q", got)
+	}
+}
+
+func TestProvider_GetWrongKey(t *testing.T) {
+	t.Parallel()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("age.GenerateX25519Identity: %s", err)
+	}
+
+	provider, err := sops.New(strings.NewReader(identity.String()))
+	if err != nil {
+		t.Fatalf("sops.New: %s", err)
+	}
+
+	if _, err := provider.Get(filepath.Join("testdata", "secrets.enc.yaml") + "#api_key"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -0,0 +1,79 @@
+package vault
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// DefaultKubernetesTokenPath is where Kubernetes mounts the service account token.
+const DefaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// Authenticator logs in to Vault using an auth method.
+type Authenticator interface {
+	// Login returns the secret including the token, client does not include a token.
+	Login(ctx context.Context, client *api.Client) (*api.Secret, error)
+}
+
+// AppRole logs in using the AppRole auth method, Mount defaults to "approle".
+type AppRole struct {
+	Mount    string
+	RoleID   string
+	SecretID string
+}
+
+// Login logs in using the role and secret IDs.
+func (a AppRole) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	secret, err := client.Logical().WriteWithContext(ctx, loginPath(a.Mount, "approle"), map[string]interface{}{
+		"role_id":   a.RoleID,
+		"secret_id": a.SecretID,
+	})
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "Logical.Write")
+	}
+
+	return secret, nil
+}
+
+// Kubernetes logs in using the Kubernetes auth method, Mount defaults to "kubernetes" and TokenPath to
+// DefaultKubernetesTokenPath.
+type Kubernetes struct {
+	Mount     string
+	Role      string
+	TokenPath string
+}
+
+// Login logs in using the service account token, it is read every time because Kubernetes rotates it.
+func (k Kubernetes) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	path := k.TokenPath
+	if path == "" {
+		path = DefaultKubernetesTokenPath
+	}
+
+	jwt, err := os.ReadFile(path)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "os.ReadFile")
+	}
+
+	secret, err := client.Logical().WriteWithContext(ctx, loginPath(k.Mount, "kubernetes"), map[string]interface{}{
+		"role": k.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "Logical.Write")
+	}
+
+	return secret, nil
+}
+
+func loginPath(mount, defaultMount string) string {
+	if mount == "" {
+		mount = defaultMount
+	}
+
+	return "auth/" + strings.Trim(mount, "/") + "/login"
+}
@@ -0,0 +1,66 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// Credentials are the short-lived database credentials issued by the database secrets engine.
+type Credentials struct {
+	Username string
+	Password string
+	// LeaseDuration is how long the credentials are valid since they were issued.
+	LeaseDuration time.Duration
+	ExpiresAt     time.Time
+}
+
+// DatabaseCredentials returns the credentials of role issued by the database secrets engine, they are reused until
+// less than a third of their lease is left, then new ones are issued; credentials without lease are always reused.
+// Connections using previous credentials must be closed before the lease expires, for example by setting their max
+// lifetime to a third of the lease.
+func (p *Provider) DatabaseCredentials(ctx context.Context, role string) (Credentials, error) {
+	p.credsMu.Lock()
+	defer p.credsMu.Unlock()
+
+	if creds, ok := p.creds[role]; ok && (creds.LeaseDuration == 0 || time.Until(creds.ExpiresAt) > creds.LeaseDuration/3) {
+		return creds, nil
+	}
+
+	mount := strings.Trim(p.databaseMount, "/")
+	if mount == "" {
+		mount = "database"
+	}
+
+	secret, err := p.client.Logical().ReadWithContext(ctx, fmt.Sprintf("%s/creds/%s", mount, role))
+	if err != nil {
+		return Credentials{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "Logical.Read")
+	}
+
+	if secret == nil {
+		return Credentials{}, internal.NewErrorf(internal.ErrorCodeNotFound, "role not found")
+	}
+
+	username, _ := secret.Data["username"].(string)
+	password, _ := secret.Data["password"].(string)
+
+	if username == "" || password == "" {
+		return Credentials{}, internal.NewErrorf(internal.ErrorCodeUnkown, "invalid credentials in secret")
+	}
+
+	lease := time.Duration(secret.LeaseDuration) * time.Second
+
+	creds := Credentials{
+		Username:      username,
+		Password:      password,
+		LeaseDuration: lease,
+		ExpiresAt:     time.Now().Add(lease),
+	}
+
+	p.creds[role] = creds
+
+	return creds, nil
+}
@@ -1,44 +1,116 @@
 package vault
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/vault/api"
+	"go.uber.org/zap"
 
 	"github.com/sanLimbu/todo-api/internal"
 )
 
+const (
+	// loginTimeout is how long logging in waits for Vault to respond.
+	loginTimeout = 10 * time.Second
+
+	// loginRetryInterval is how long to wait before logging in again after failing.
+	loginRetryInterval = 5 * time.Second
+)
+
+// Config defines how to connect to Vault.
+type Config struct {
+	Address string
+	// Token is used when Auth is nil, it is renewed until it reaches its max TTL.
+	Token string
+	// Auth logs in for getting a token, the token is renewed and when that's not possible anymore it logs in again.
+	Auth Authenticator
+	// Path is where the KV secrets engine is enabled.
+	Path string
+	// KVRefresh is how long KV secrets are cached before reading them again, zero caches them forever.
+	KVRefresh time.Duration
+	// DatabaseMount is where the database secrets engine is enabled.
+	DatabaseMount string
+}
+
 // Provider ...
 type Provider struct {
-	path    string
-	client  *api.Logical
-	results map[string]map[string]string
+	client        *api.Client
+	auth          Authenticator
+	path          string
+	refresh       time.Duration
+	databaseMount string
+	logger        *zap.Logger
+
+	mu      sync.Mutex
+	results map[string]kvSecret
+
+	credsMu sync.Mutex
+	creds   map[string]Credentials
+
+	closeC chan struct{}
+	doneC  chan struct{}
 }
 
-// New ...
-func New(token, addr, path string) (*Provider, error) {
-	config := &api.Config{
-		Address: addr,
-	}
+type kvSecret struct {
+	data   map[string]string
+	readAt time.Time
+}
 
-	client, err := api.NewClient(config)
+// New instantiates the Provider, when conf.Auth is defined it logs in before returning. The token is renewed in the
+// background until Close is called.
+func New(conf Config, logger *zap.Logger) (*Provider, error) {
+	client, err := api.NewClient(&api.Config{
+		Address: conf.Address,
+	})
 	if err != nil {
 		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "api.NewClient")
 	}
 
-	client.SetToken(token)
+	var auth *api.Secret
+
+	if conf.Auth != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+		defer cancel()
+
+		if auth, err = login(ctx, client, conf.Auth); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "login")
+		}
+	} else {
+		client.SetToken(conf.Token)
+	}
+
+	p := &Provider{
+		client:        client,
+		auth:          conf.Auth,
+		path:          conf.Path,
+		refresh:       conf.KVRefresh,
+		databaseMount: conf.DatabaseMount,
+		logger:        logger,
+		results:       make(map[string]kvSecret),
+		creds:         make(map[string]Credentials),
+		closeC:        make(chan struct{}),
+		doneC:         make(chan struct{}),
+	}
+
+	go p.renewToken(auth)
+
+	return p, nil
+}
 
-	return &Provider{
-		path:    path,
-		client:  client.Logical(),
-		results: make(map[string]map[string]string),
-	}, nil
+// Close stops renewing the token.
+func (p *Provider) Close() {
+	close(p.closeC)
+	<-p.doneC
 }
 
 // Get retrieves a value from vault using the KV engine. The actual key selected is determined by the value
 // separated by the colon. For example "database:password" will retrieve the key "password" from the path
-// "database".
+// "database". Secrets are read again once they are cached for longer than the configured refresh interval, if that
+// fails the cached values are used.
 func (p *Provider) Get(v string) (string, error) {
 	// <path>/data/<path-secret>:key
 	split := strings.Split(v, ":")
@@ -49,28 +121,47 @@ func (p *Provider) Get(v string) (string, error) {
 	pathSecret := split[0]
 	key := split[1]
 
+	p.mu.Lock()
 	res, ok := p.results[pathSecret]
-	if ok {
-		val, ok := res[key]
-		if !ok {
-			return "", internal.NewErrorf(internal.ErrorCodeUnkown, "key not found in cached data")
+	p.mu.Unlock()
+
+	if !ok || (p.refresh > 0 && time.Since(res.readAt) >= p.refresh) {
+		secrets, err := p.read(pathSecret)
+		switch {
+		case err == nil:
+			res = kvSecret{data: secrets, readAt: time.Now()}
+
+			p.mu.Lock()
+			p.results[pathSecret] = res
+			p.mu.Unlock()
+		case ok:
+			p.logger.Warn("Couldn't read secret again, using cached values", zap.String("path", pathSecret), zap.Error(err))
+		default:
+			return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "read")
 		}
+	}
 
-		return val, nil
+	val, ok := res.data[key]
+	if !ok {
+		return "", internal.NewErrorf(internal.ErrorCodeUnkown, "key not found in retrieved data")
 	}
 
-	secret, err := p.client.Read(fmt.Sprintf("%s/data/%s", p.path, pathSecret))
+	return val, nil
+}
+
+func (p *Provider) read(pathSecret string) (map[string]string, error) {
+	secret, err := p.client.Logical().Read(fmt.Sprintf("%s/data/%s", p.path, pathSecret))
 	if err != nil {
-		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "reading")
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "reading")
 	}
 
 	if secret == nil {
-		return "", internal.NewErrorf(internal.ErrorCodeUnkown, "secret not found")
+		return nil, internal.NewErrorf(internal.ErrorCodeUnkown, "secret not found")
 	}
 
 	data, ok := secret.Data["data"].(map[string]interface{})
 	if !ok {
-		return "", internal.NewErrorf(internal.ErrorCodeUnkown, "invalid data in secret")
+		return nil, internal.NewErrorf(internal.ErrorCodeUnkown, "invalid data in secret")
 	}
 
 	secrets := make(map[string]string)
@@ -78,18 +169,158 @@ func (p *Provider) Get(v string) (string, error) {
 	for k, v := range data {
 		val, ok := v.(string)
 		if !ok {
-			return "", internal.NewErrorf(internal.ErrorCodeUnkown, "secret value in data is not string")
+			return nil, internal.NewErrorf(internal.ErrorCodeUnkown, "secret value in data is not string")
 		}
 
 		secrets[k] = val
 	}
 
-	val, ok := secrets[key]
-	if !ok {
-		return "", internal.NewErrorf(internal.ErrorCodeUnkown, "key not found in retrieved data")
+	return secrets, nil
+}
+
+// renewToken renews the token until it reaches its max TTL, then it logs in again when possible. auth is nil when
+// using a static token, in that case it is looked up first because root tokens don't expire.
+func (p *Provider) renewToken(auth *api.Secret) {
+	defer close(p.doneC)
+
+	if auth == nil {
+		if p.client.Token() == "" {
+			return
+		}
+
+		var err error
+
+		if auth, err = p.renewableToken(); err != nil {
+			p.logger.Warn("Couldn't renew Vault token", zap.Error(err))
+
+			return
+		}
+
+		if auth == nil {
+			return
+		}
 	}
 
-	p.results[pathSecret] = secrets
+	for {
+		if !p.watch(auth) {
+			return
+		}
 
-	return val, nil
+		if p.auth == nil {
+			p.logger.Error("Vault token can't be renewed anymore")
+
+			return
+		}
+
+		var ok bool
+
+		if auth, ok = p.login(); !ok {
+			return
+		}
+	}
+}
+
+// renewableToken renews the static token once for getting its lease, it returns nil when the token does not expire.
+func (p *Provider) renewableToken() (*api.Secret, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+	defer cancel()
+
+	secret, err := p.client.Auth().Token().LookupSelfWithContext(ctx)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "LookupSelf")
+	}
+
+	renewable, err := secret.TokenIsRenewable()
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "TokenIsRenewable")
+	}
+
+	ttl, err := secret.TokenTTL()
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "TokenTTL")
+	}
+
+	if !renewable || ttl == 0 {
+		return nil, nil
+	}
+
+	secret, err = p.client.Auth().Token().RenewSelfWithContext(ctx, 0)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "RenewSelf")
+	}
+
+	return secret, nil
+}
+
+// watch renews auth until that's not possible anymore, it returns false when the Provider is closed.
+func (p *Provider) watch(auth *api.Secret) bool {
+	watcher, err := p.client.NewLifetimeWatcher(&api.LifetimeWatcherInput{
+		Secret: auth,
+	})
+	if err != nil {
+		p.logger.Error("Couldn't renew Vault token", zap.Error(err))
+
+		return true
+	}
+
+	go watcher.Start()
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-p.closeC:
+			return false
+		case err := <-watcher.DoneCh():
+			if err != nil {
+				p.logger.Warn("Couldn't renew Vault token", zap.Error(err))
+			}
+
+			return true
+		case <-watcher.RenewCh():
+		}
+	}
+}
+
+// login logs in until it succeeds, it returns false when the Provider is closed.
+func (p *Provider) login() (*api.Secret, bool) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+		auth, err := login(ctx, p.client, p.auth)
+		cancel()
+
+		if err == nil {
+			return auth, true
+		}
+
+		p.logger.Error("Couldn't log in to Vault, retrying", zap.Error(err))
+
+		select {
+		case <-p.closeC:
+			return nil, false
+		case <-time.After(loginRetryInterval):
+		}
+	}
+}
+
+func login(ctx context.Context, client *api.Client, auth Authenticator) (*api.Secret, error) {
+	// Login endpoints don't require a token, the expired one may be rejected.
+	c, err := client.Clone()
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Clone")
+	}
+
+	c.ClearToken()
+
+	secret, err := auth.Login(ctx, c)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "auth.Login")
+	}
+
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, internal.NewErrorf(internal.ErrorCodeUnkown, "login did not return a token")
+	}
+
+	client.SetToken(secret.Auth.ClientToken)
+
+	return secret, nil
 }
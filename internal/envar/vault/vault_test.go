@@ -0,0 +1,347 @@
+package vault_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/envar/vault"
+)
+
+// server is a stub of the Vault HTTP API, it records the requests so tests can check the token used by each one.
+type server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests map[string][]request
+}
+
+type request struct {
+	token string
+	body  map[string]interface{}
+}
+
+func newServer(t *testing.T, handlers map[string]func(req request) (int, interface{})) *server {
+	t.Helper()
+
+	srv := &server{requests: make(map[string][]request)}
+
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+
+		req := request{token: r.Header.Get("X-Vault-Token")}
+		_ = json.NewDecoder(r.Body).Decode(&req.body)
+
+		srv.mu.Lock()
+		srv.requests[key] = append(srv.requests[key], req)
+		srv.mu.Unlock()
+
+		handler, ok := handlers[key]
+		if !ok {
+			t.Errorf("unexpected request %s", key)
+
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+
+			return
+		}
+
+		status, res := handler(req)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// received returns the requests received for key, "<method> <path>".
+func (s *server) received(key string) []request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]request(nil), s.requests[key]...)
+}
+
+// waitFor polls until at least n requests were received for key.
+func (s *server) waitFor(t *testing.T, key string, n int) []request {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		if reqs := s.received(key); len(reqs) >= n {
+			return reqs
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d requests for %s, got %d", n, key, len(s.received(key)))
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func authResponse(token string, lease int) map[string]interface{} {
+	return map[string]interface{}{
+		"auth": map[string]interface{}{
+			"client_token":   token,
+			"lease_duration": lease,
+			"renewable":      true,
+		},
+	}
+}
+
+func newProvider(t *testing.T, conf vault.Config) *vault.Provider {
+	t.Helper()
+
+	provider, err := vault.New(conf, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+
+	t.Cleanup(provider.Close)
+
+	return provider
+}
+
+func TestNew_Token(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t, map[string]func(request) (int, interface{}){
+		"GET /v1/auth/token/lookup-self": func(request) (int, interface{}) {
+			return http.StatusOK, map[string]interface{}{
+				"data": map[string]interface{}{"ttl": 3600, "renewable": true},
+			}
+		},
+		"PUT /v1/auth/token/renew-self": func(req request) (int, interface{}) {
+			return http.StatusOK, authResponse(req.token, 3600)
+		},
+	})
+
+	newProvider(t, vault.Config{Address: srv.URL, Token: "static"})
+
+	renewals := srv.waitFor(t, "PUT /v1/auth/token/renew-self", 1)
+
+	if renewals[0].token != "static" {
+		t.Fatalf("expected the static token to be renewed, got %q", renewals[0].token)
+	}
+}
+
+func TestNew_TokenNotExpiring(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t, map[string]func(request) (int, interface{}){
+		"GET /v1/auth/token/lookup-self": func(request) (int, interface{}) {
+			return http.StatusOK, map[string]interface{}{
+				"data": map[string]interface{}{"ttl": 0, "renewable": false},
+			}
+		},
+	})
+
+	provider, err := vault.New(vault.Config{Address: srv.URL, Token: "root"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+
+	// Close waits for the lookup, root tokens are not renewed afterwards.
+	provider.Close()
+
+	if n := len(srv.received("GET /v1/auth/token/lookup-self")); n != 1 {
+		t.Fatalf("expected the token to be looked up once, got %d", n)
+	}
+}
+
+func TestNew_AppRole(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		logins int
+	)
+
+	srv := newServer(t, map[string]func(request) (int, interface{}){
+		"PUT /v1/auth/approle/login": func(req request) (int, interface{}) {
+			if req.token != "" {
+				t.Errorf("expected login without token, got %q", req.token)
+			}
+
+			if req.body["role_id"] != "role" || req.body["secret_id"] != "secret" {
+				t.Errorf("unexpected login %v", req.body)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			logins++
+
+			return http.StatusOK, authResponse(fmt.Sprintf("token-%d", logins), 2)
+		},
+		"PUT /v1/auth/token/renew-self": func(req request) (int, interface{}) {
+			// The first token reached its max TTL, so it has to log in again.
+			if req.token == "token-1" {
+				return http.StatusOK, authResponse(req.token, 0)
+			}
+
+			return http.StatusOK, authResponse(req.token, 3600)
+		},
+		"GET /v1/secret/data/app": func(req request) (int, interface{}) {
+			return http.StatusOK, map[string]interface{}{
+				"data": map[string]interface{}{
+					"data": map[string]interface{}{"password": "p4ss"},
+				},
+			}
+		},
+	})
+
+	provider := newProvider(t, vault.Config{
+		Address: srv.URL,
+		Auth:    vault.AppRole{RoleID: "role", SecretID: "secret"},
+		Path:    "secret",
+	})
+
+	srv.waitFor(t, "PUT /v1/auth/approle/login", 2)
+	renewals := srv.waitFor(t, "PUT /v1/auth/token/renew-self", 2)
+
+	if renewals[0].token != "token-1" || renewals[1].token != "token-2" {
+		t.Fatalf("unexpected renewed tokens %q and %q", renewals[0].token, renewals[1].token)
+	}
+
+	val, err := provider.Get("app:password")
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+
+	if val != "p4ss" {
+		t.Fatalf("expected p4ss, got %q", val)
+	}
+
+	if reads := srv.received("GET /v1/secret/data/app"); reads[0].token != "token-2" {
+		t.Fatalf("expected the secret to be read using the new token, got %q", reads[0].token)
+	}
+}
+
+func TestNew_Kubernetes(t *testing.T) {
+	t.Parallel()
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+
+	if err := os.WriteFile(tokenPath, []byte("service-account-jwt\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	srv := newServer(t, map[string]func(request) (int, interface{}){
+		"PUT /v1/auth/k8s/login": func(req request) (int, interface{}) {
+			if req.body["role"] != "todo-api" || req.body["jwt"] != "service-account-jwt" {
+				t.Errorf("unexpected login %v", req.body)
+			}
+
+			return http.StatusOK, authResponse("k8s-token", 3600)
+		},
+		"PUT /v1/auth/token/renew-self": func(req request) (int, interface{}) {
+			return http.StatusOK, authResponse(req.token, 3600)
+		},
+	})
+
+	newProvider(t, vault.Config{
+		Address: srv.URL,
+		Auth:    vault.Kubernetes{Mount: "/k8s/", Role: "todo-api", TokenPath: tokenPath},
+	})
+
+	if renewals := srv.waitFor(t, "PUT /v1/auth/token/renew-self", 1); renewals[0].token != "k8s-token" {
+		t.Fatalf("expected the issued token to be renewed, got %q", renewals[0].token)
+	}
+}
+
+func TestNew_LoginFailed(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t, map[string]func(request) (int, interface{}){
+		"PUT /v1/auth/approle/login": func(request) (int, interface{}) {
+			return http.StatusBadRequest, map[string]interface{}{"errors": []string{"invalid role or secret ID"}}
+		},
+	})
+
+	_, err := vault.New(vault.Config{
+		Address: srv.URL,
+		Auth:    vault.AppRole{RoleID: "role", SecretID: "wrong"},
+	}, zap.NewNop())
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestProvider_DatabaseCredentials(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		issued int
+	)
+
+	srv := newServer(t, map[string]func(request) (int, interface{}){
+		"GET /v1/db/creds/app": func(request) (int, interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			issued++
+
+			return http.StatusOK, map[string]interface{}{
+				"lease_id":       fmt.Sprintf("db/creds/app/%d", issued),
+				"lease_duration": 1,
+				"renewable":      true,
+				"data": map[string]interface{}{
+					"username": fmt.Sprintf("user-%d", issued),
+					"password": "secret",
+				},
+			}
+		},
+		"GET /v1/db/creds/unknown": func(request) (int, interface{}) {
+			return http.StatusNotFound, map[string]interface{}{"errors": []string{}}
+		},
+	})
+
+	provider := newProvider(t, vault.Config{Address: srv.URL, DatabaseMount: "/db/"})
+	ctx := context.Background()
+
+	creds, err := provider.DatabaseCredentials(ctx, "app")
+	if err != nil {
+		t.Fatalf("DatabaseCredentials: %s", err)
+	}
+
+	if creds.Username != "user-1" || creds.Password != "secret" || creds.LeaseDuration != time.Second {
+		t.Fatalf("unexpected credentials %+v", creds)
+	}
+
+	// Credentials are reused while more than a third of the lease is left.
+	if creds, err = provider.DatabaseCredentials(ctx, "app"); err != nil || creds.Username != "user-1" {
+		t.Fatalf("expected cached credentials, got %+v: %v", creds, err)
+	}
+
+	time.Sleep(time.Until(creds.ExpiresAt.Add(-creds.LeaseDuration / 3)))
+
+	if creds, err = provider.DatabaseCredentials(ctx, "app"); err != nil || creds.Username != "user-2" {
+		t.Fatalf("expected rotated credentials, got %+v: %v", creds, err)
+	}
+
+	_, err = provider.DatabaseCredentials(ctx, "unknown")
+
+	var ierr *internal.Error
+	if !errors.As(err, &ierr) || ierr.Code() != internal.ErrorCodeNotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
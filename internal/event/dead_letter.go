@@ -0,0 +1,28 @@
+package event
+
+import (
+	"time"
+)
+
+// DeadLetter represents a message that couldn't be processed by a consumer after exhausting all retries.
+type DeadLetter struct {
+	// ID identifies the dead letter in the message broker holding it.
+	ID string
+
+	// Destination indicates where the message was originally published to: topic, routing key or channel.
+	Destination string
+
+	// Error is the reason that caused dead lettering the message, it may be empty when the message broker
+	// dead letters messages by itself.
+	Error string
+
+	// FailedAt indicates when the message was dead lettered.
+	FailedAt time.Time
+
+	// ContentType is the media type of Body, it may be empty for messages published before content types were
+	// defined.
+	ContentType string
+
+	// Body is the original message, usually an encoded Envelope.
+	Body []byte
+}
@@ -0,0 +1,163 @@
+// Package event defines the envelope used for publishing Task events to any message broker. The envelope follows
+// the CloudEvents 1.0 format so consumers written in other languages can use existing SDKs for reading them, it is
+// encoded as JSON or Protobuf depending on the Serializer.
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+const (
+	// TypeTaskCreated indicates a task was created.
+	TypeTaskCreated = "tasks.event.created"
+
+	// TypeTaskUpdated indicates a task was updated.
+	TypeTaskUpdated = "tasks.event.updated"
+
+	// TypeTaskDeleted indicates a task was deleted, only the task ID and the version of the deletion are included in
+	// the payload.
+	TypeTaskDeleted = "tasks.event.deleted"
+)
+
+const (
+	// SchemaVersion is the version of the payload, it changes when the payload changes in an incompatible way.
+	SchemaVersion = 1
+
+	specVersion = "1.0"
+	source      = "/todo-api/tasks"
+)
+
+// Envelope wraps the payload of every published event.
+type Envelope struct {
+	SpecVersion   string    `json:"specversion"`
+	ID            string    `json:"id"`
+	Source        string    `json:"source"`
+	Type          string    `json:"type"`
+	Subject       string    `json:"subject,omitempty"`
+	Time          time.Time `json:"time"`
+	SchemaVersion int       `json:"schemaversion"`
+	TraceParent   string    `json:"traceparent,omitempty"`
+	TraceState    string    `json:"tracestate,omitempty"`
+	Data          Task      `json:"data"`
+}
+
+// Task is the payload of Task events.
+type Task struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	IsDone      bool       `json:"is_done,omitempty"`
+	Version     int64      `json:"version,omitempty"`
+}
+
+// NewTask instantiates the envelope of an event about a task, the trace context in ctx is included.
+func NewTask(ctx context.Context, eventType string, task internal.Task) Envelope {
+	payload := Task{
+		ID:      task.ID,
+		Version: task.Version,
+	}
+
+	if eventType != TypeTaskDeleted {
+		payload.Description = task.Description
+		payload.Priority = task.Priority.String()
+		payload.StartDate = newTime(task.Dates.Start)
+		payload.DueDate = newTime(task.Dates.Due)
+		payload.IsDone = task.IsDone
+	}
+
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	return Envelope{
+		SpecVersion:   specVersion,
+		ID:            uuid.NewString(),
+		Source:        source,
+		Type:          eventType,
+		Subject:       task.ID,
+		Time:          time.Now().UTC(),
+		SchemaVersion: SchemaVersion,
+		TraceParent:   carrier.Get("traceparent"),
+		TraceState:    carrier.Get("tracestate"),
+		Data:          payload,
+	}
+}
+
+// validate checks the envelope is supported, it is called after decoding.
+func (e Envelope) validate() error {
+	if e.SpecVersion != specVersion {
+		return internal.NewErrorf(internal.ErrorCodeInvalidArgument, "unsupported specversion %q", e.SpecVersion)
+	}
+
+	if e.SchemaVersion != SchemaVersion {
+		return internal.NewErrorf(internal.ErrorCodeInvalidArgument, "unsupported schemaversion %d", e.SchemaVersion)
+	}
+
+	return nil
+}
+
+// Task returns the task included in the payload.
+func (e Envelope) Task() (internal.Task, error) {
+	payload := e.Data
+
+	// Deleted events don't include the priority.
+	priority := internal.PriorityNone
+
+	if payload.Priority != "" {
+		var err error
+
+		if priority, err = internal.ParsePriority(payload.Priority); err != nil {
+			return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "internal.ParsePriority")
+		}
+	}
+
+	res := internal.Task{
+		ID:          payload.ID,
+		Description: payload.Description,
+		Priority:    priority,
+		IsDone:      payload.IsDone,
+		Version:     payload.Version,
+	}
+
+	if payload.StartDate != nil {
+		res.Dates.Start = *payload.StartDate
+	}
+
+	if payload.DueDate != nil {
+		res.Dates.Due = *payload.DueDate
+	}
+
+	return res, nil
+}
+
+// TraceContext returns the propagated trace context as a carrier that can be used for extracting it.
+func (e Envelope) TraceContext() propagation.MapCarrier {
+	carrier := propagation.MapCarrier{}
+
+	if e.TraceParent != "" {
+		carrier.Set("traceparent", e.TraceParent)
+	}
+
+	if e.TraceState != "" {
+		carrier.Set("tracestate", e.TraceState)
+	}
+
+	return carrier
+}
+
+func newTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.UTC()
+
+	return &t
+}
@@ -0,0 +1,13 @@
+// Package eventpb defines the Protobuf messages used for publishing Task events.
+package eventpb
+
+import (
+	_ "embed"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative event.proto
+
+// Schema is the Protobuf definition of the messages, it is registered in the schema registry.
+//
+//go:embed event.proto
+var Schema string
@@ -0,0 +1,48 @@
+package event
+
+import (
+	"encoding/json"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// ContentTypeJSON is the media type of envelopes encoded by JSON.
+const ContentTypeJSON = "application/cloudevents+json"
+
+// JSON encodes envelopes using the CloudEvents JSON format.
+type JSON struct{}
+
+type jsonEnvelope struct {
+	Envelope
+	DataContentType string `json:"datacontenttype"`
+}
+
+// ContentType returns ContentTypeJSON.
+func (JSON) ContentType() string {
+	return ContentTypeJSON
+}
+
+// Marshal returns the JSON representation of the envelope.
+func (JSON) Marshal(e Envelope) ([]byte, error) {
+	b, err := json.Marshal(jsonEnvelope{Envelope: e, DataContentType: "application/json"})
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Marshal")
+	}
+
+	return b, nil
+}
+
+// Unmarshal parses the JSON representation of an envelope.
+func (JSON) Unmarshal(b []byte) (Envelope, error) {
+	var res jsonEnvelope
+
+	if err := json.Unmarshal(b, &res); err != nil {
+		return Envelope{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "json.Unmarshal")
+	}
+
+	if err := res.validate(); err != nil {
+		return Envelope{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "validate")
+	}
+
+	return res.Envelope, nil
+}
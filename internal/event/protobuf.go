@@ -0,0 +1,230 @@
+package event
+
+import (
+	"encoding/binary"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event/eventpb"
+)
+
+// ContentTypeProtobuf is the media type of envelopes encoded by Protobuf.
+const ContentTypeProtobuf = "application/protobuf"
+
+// magicByte starts every message using the schema registry wire format, it never starts a plain Protobuf message
+// because zero is not a valid field tag.
+const magicByte = 0
+
+// Protobuf encodes envelopes using the eventpb.Envelope message. Envelopes can optionally use the Confluent schema
+// registry wire format: a magic byte, the schema ID and the index of the message in the schema precede the message.
+// Both are decoded regardless of how the Protobuf instance was created.
+type Protobuf struct {
+	schemaID uint32
+	framed   bool
+}
+
+// NewProtobuf instantiates the Protobuf serializer.
+func NewProtobuf() *Protobuf {
+	return &Protobuf{}
+}
+
+// NewProtobufSchemaRegistry instantiates the Protobuf serializer using the schema registry wire format, schemaID
+// is the ID returned when registering eventpb.Schema.
+func NewProtobufSchemaRegistry(schemaID int) *Protobuf {
+	return &Protobuf{
+		schemaID: uint32(schemaID),
+		framed:   true,
+	}
+}
+
+// ContentType returns ContentTypeProtobuf.
+func (p *Protobuf) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+// Marshal returns the Protobuf representation of the envelope.
+func (p *Protobuf) Marshal(e Envelope) ([]byte, error) {
+	var b []byte
+
+	if p.framed {
+		b = append(b, magicByte)
+		b = binary.BigEndian.AppendUint32(b, p.schemaID)
+
+		// The message indexes of the first message in the schema, eventpb.Envelope, are encoded as a single zero.
+		b = protowire.AppendVarint(b, 0)
+	}
+
+	b, err := proto.MarshalOptions{}.MarshalAppend(b, newProtobufEnvelope(e))
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "proto.Marshal")
+	}
+
+	return b, nil
+}
+
+// Unmarshal parses the Protobuf representation of an envelope.
+func (p *Protobuf) Unmarshal(b []byte) (Envelope, error) {
+	if len(b) > 0 && b[0] == magicByte {
+		var err error
+
+		if b, err = unframe(b); err != nil {
+			return Envelope{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "unframe")
+		}
+	}
+
+	var msg eventpb.Envelope
+
+	if err := proto.Unmarshal(b, &msg); err != nil {
+		return Envelope{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "proto.Unmarshal")
+	}
+
+	res := convertProtobufEnvelope(&msg)
+
+	if err := res.validate(); err != nil {
+		return Envelope{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "validate")
+	}
+
+	return res, nil
+}
+
+// unframe returns the message following the schema registry header, the message must be eventpb.Envelope.
+func unframe(b []byte) ([]byte, error) {
+	if len(b) < 5 {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "header too short")
+	}
+
+	b = b[5:] // magic byte and schema ID
+
+	// Message indexes are an array of zigzag encoded varints preceded by its length, an empty array refers to the
+	// first message.
+	count, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "invalid message indexes")
+	}
+
+	b = b[n:]
+
+	for i := int64(0); i < protowire.DecodeZigZag(count); i++ {
+		index, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "invalid message index")
+		}
+
+		if i > 0 || protowire.DecodeZigZag(index) != 0 {
+			return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "unexpected message type")
+		}
+
+		b = b[n:]
+	}
+
+	return b, nil
+}
+
+func newProtobufEnvelope(e Envelope) *eventpb.Envelope {
+	return &eventpb.Envelope{
+		SpecVersion:   e.SpecVersion,
+		Id:            e.ID,
+		Source:        e.Source,
+		Type:          e.Type,
+		Subject:       e.Subject,
+		Time:          timestamppb.New(e.Time),
+		SchemaVersion: int32(e.SchemaVersion),
+		TraceParent:   e.TraceParent,
+		TraceState:    e.TraceState,
+		Data: &eventpb.Task{
+			Id:          e.Data.ID,
+			Description: e.Data.Description,
+			Priority:    newProtobufPriority(e.Data.Priority),
+			StartDate:   newTimestamp(e.Data.StartDate),
+			DueDate:     newTimestamp(e.Data.DueDate),
+			IsDone:      e.Data.IsDone,
+			Version:     e.Data.Version,
+		},
+	}
+}
+
+func convertProtobufEnvelope(msg *eventpb.Envelope) Envelope {
+	res := Envelope{
+		SpecVersion:   msg.GetSpecVersion(),
+		ID:            msg.GetId(),
+		Source:        msg.GetSource(),
+		Type:          msg.GetType(),
+		Subject:       msg.GetSubject(),
+		SchemaVersion: int(msg.GetSchemaVersion()),
+		TraceParent:   msg.GetTraceParent(),
+		TraceState:    msg.GetTraceState(),
+	}
+
+	if msg.GetTime() != nil {
+		res.Time = msg.GetTime().AsTime()
+	}
+
+	if data := msg.GetData(); data != nil {
+		res.Data = Task{
+			ID:          data.GetId(),
+			Description: data.GetDescription(),
+			Priority:    convertProtobufPriority(data.GetPriority()),
+			StartDate:   convertTimestamp(data.GetStartDate()),
+			DueDate:     convertTimestamp(data.GetDueDate()),
+			IsDone:      data.GetIsDone(),
+			Version:     data.GetVersion(),
+		}
+	}
+
+	return res
+}
+
+func newTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+func convertTimestamp(t *timestamppb.Timestamp) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	res := t.AsTime()
+
+	return &res
+}
+
+func newProtobufPriority(p string) eventpb.Priority {
+	switch p {
+	case "none":
+		return eventpb.Priority_PRIORITY_NONE
+	case "low":
+		return eventpb.Priority_PRIORITY_LOW
+	case "medium":
+		return eventpb.Priority_PRIORITY_MEDIUM
+	case "high":
+		return eventpb.Priority_PRIORITY_HIGH
+	}
+
+	return eventpb.Priority_PRIORITY_UNSPECIFIED
+}
+
+// convertProtobufPriority returns unknown values as is so Envelope.Task rejects them.
+func convertProtobufPriority(p eventpb.Priority) string {
+	switch p {
+	case eventpb.Priority_PRIORITY_UNSPECIFIED:
+		return ""
+	case eventpb.Priority_PRIORITY_NONE:
+		return "none"
+	case eventpb.Priority_PRIORITY_LOW:
+		return "low"
+	case eventpb.Priority_PRIORITY_MEDIUM:
+		return "medium"
+	case eventpb.Priority_PRIORITY_HIGH:
+		return "high"
+	}
+
+	return p.String()
+}
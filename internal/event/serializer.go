@@ -0,0 +1,63 @@
+package event
+
+import (
+	"mime"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// Serializer encodes and decodes envelopes using a specific format.
+type Serializer interface {
+	// ContentType returns the media type of the encoded envelopes, message brokers include it in the message
+	// metadata so consumers know how to decode them.
+	ContentType() string
+	Marshal(e Envelope) ([]byte, error)
+	Unmarshal(b []byte) (Envelope, error)
+}
+
+// Message represents an encoded envelope received from a message broker.
+type Message struct {
+	// ContentType is the media type of Body, messages published before content types were defined don't include
+	// it and they are JSON.
+	ContentType string
+
+	// Headers holds the trace context propagated in the message metadata, it is nil when the message broker doesn't
+	// support headers. The envelope includes the trace context as well.
+	Headers propagation.TextMapCarrier
+
+	Body []byte
+}
+
+// serializers indicates the Serializer used for decoding each supported media type.
+var serializers = map[string]Serializer{
+	ContentTypeJSON:     JSON{},
+	ContentTypeProtobuf: NewProtobuf(),
+}
+
+// Unmarshal decodes msg using the Serializer matching its content type.
+func Unmarshal(msg Message) (Envelope, error) {
+	contentType := ContentTypeJSON
+
+	if msg.ContentType != "" {
+		mediaType, _, err := mime.ParseMediaType(msg.ContentType)
+		if err != nil {
+			return Envelope{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "mime.ParseMediaType")
+		}
+
+		contentType = mediaType
+	}
+
+	serializer, ok := serializers[contentType]
+	if !ok {
+		return Envelope{}, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "unsupported content type %q", msg.ContentType)
+	}
+
+	res, err := serializer.Unmarshal(msg.Body)
+	if err != nil {
+		return Envelope{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "serializer.Unmarshal")
+	}
+
+	return res, nil
+}
@@ -0,0 +1,55 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/service"
+)
+
+// Task represents the repository used for publishing Task events to several message brokers at once, it is meant
+// to be used while migrating from one message broker to another. With no message brokers events are discarded.
+type Task struct {
+	brokers []service.TaskMessageBrokerRepository
+}
+
+// NewTask instantiates the Task repository.
+func NewTask(brokers ...service.TaskMessageBrokerRepository) *Task {
+	return &Task{
+		brokers: brokers,
+	}
+}
+
+// Created publishes a message indicating a task was created.
+func (t *Task) Created(ctx context.Context, task internal.Task) error {
+	return t.publish(func(b service.TaskMessageBrokerRepository) error { return b.Created(ctx, task) })
+}
+
+// Deleted publishes a message indicating a task was deleted.
+func (t *Task) Deleted(ctx context.Context, id string, version int64) error {
+	return t.publish(func(b service.TaskMessageBrokerRepository) error { return b.Deleted(ctx, id, version) })
+}
+
+// Updated publishes a message indicating a task was updated.
+func (t *Task) Updated(ctx context.Context, task internal.Task) error {
+	return t.publish(func(b service.TaskMessageBrokerRepository) error { return b.Updated(ctx, task) })
+}
+
+// publish calls all the message brokers even when one of them fails, so a broken broker does not prevent the
+// others from receiving the event.
+func (t *Task) publish(fn func(b service.TaskMessageBrokerRepository) error) error {
+	var errs []error
+
+	for _, b := range t.brokers {
+		if err := fn(b); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "publish")
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Statuses reported by the checks and by the service.
+const (
+	StatusOK           = "ok"
+	StatusFailing      = "failing"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Check returns an error when the dependency can't be used.
+type Check func(ctx context.Context) error
+
+// Result is the outcome of running a Check.
+type Result struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the overall status of the service with the result of each check.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the checks of the dependencies required for serving requests. Results are cached so frequent probes
+// don't overload the dependencies, and concurrent probes run each check only once.
+type Checker struct {
+	timeout time.Duration
+	ttl     time.Duration
+	checks  []namedCheck
+
+	group        singleflight.Group
+	mu           sync.Mutex
+	results      map[string]Result
+	shuttingDown atomic.Bool
+}
+
+// NewChecker instantiates the Checker, each check is cancelled after timeout and its result is reused for ttl.
+func NewChecker(timeout, ttl time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		ttl:     ttl,
+		results: make(map[string]Result),
+	}
+}
+
+// Add registers the check of a dependency, it must be called before serving requests.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Shutdown makes readiness fail from now on, it is called when the service starts shutting down so no new work is
+// routed to it.
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready runs the checks, the service is ready when all of them succeed and it is not shutting down.
+func (c *Checker) Ready(ctx context.Context) Report {
+	res := Report{
+		Status: StatusOK,
+		Checks: make(map[string]Result, len(c.checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, nc := range c.checks {
+		wg.Add(1)
+
+		go func(nc namedCheck) {
+			defer wg.Done()
+
+			result := c.run(ctx, nc)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			res.Checks[nc.name] = result
+
+			if result.Status != StatusOK {
+				res.Status = StatusFailing
+			}
+		}(nc)
+	}
+
+	wg.Wait()
+
+	if c.shuttingDown.Load() {
+		res.Status = StatusShuttingDown
+	}
+
+	return res
+}
+
+// Register connects the liveness and readiness endpoints to the router.
+func (c *Checker) Register(r interface {
+	Handle(pattern string, h http.Handler)
+}) {
+	r.Handle("/healthz", http.HandlerFunc(c.live))
+	r.Handle("/readyz", http.HandlerFunc(c.ready))
+}
+
+// live succeeds as long as the process is serving requests, dependencies are not checked so the service is not
+// restarted when they are unavailable.
+func (c *Checker) live(w http.ResponseWriter, _ *http.Request) {
+	writeReport(w, http.StatusOK, Report{Status: StatusOK})
+}
+
+func (c *Checker) ready(w http.ResponseWriter, r *http.Request) {
+	res := c.Ready(r.Context())
+
+	status := http.StatusOK
+	if res.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeReport(w, status, res)
+}
+
+// run returns the cached result of the check when it is recent enough, otherwise it runs it. The check is not
+// cancelled when ctx is done because other probes may be waiting for it, instead it is bounded by the timeout.
+func (c *Checker) run(ctx context.Context, nc namedCheck) Result {
+	c.mu.Lock()
+	cached, ok := c.results[nc.name]
+	c.mu.Unlock()
+
+	if ok && time.Since(cached.CheckedAt) < c.ttl {
+		return cached
+	}
+
+	resC := c.group.DoChan(nc.name, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+
+		start := time.Now()
+		err := nc.check(ctx)
+
+		res := Result{
+			Status:    StatusOK,
+			Duration:  time.Since(start).String(),
+			CheckedAt: start,
+		}
+
+		if err != nil {
+			res.Status = StatusFailing
+			res.Error = err.Error()
+		}
+
+		c.mu.Lock()
+		c.results[nc.name] = res
+		c.mu.Unlock()
+
+		return res, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return Result{
+			Status:    StatusFailing,
+			Error:     ctx.Err().Error(),
+			CheckedAt: time.Now(),
+		}
+	case res := <-resC:
+		return res.Val.(Result)
+	}
+}
+
+func writeReport(w http.ResponseWriter, status int, res Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(res)
+}
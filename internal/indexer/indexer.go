@@ -0,0 +1,164 @@
+// Package indexer defines the logic shared by all consumers indexing Task events, regardless of the message broker
+// delivering them.
+package indexer
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+	"github.com/sanLimbu/todo-api/internal/retry"
+)
+
+const otelName = "github.com/sanLimbu/todo-api/internal/indexer"
+
+// Consumer represents a message broker delivering Task events.
+type Consumer interface {
+	// Consume calls handle for every received message until ctx is done. Messages are acknowledged after handle
+	// returns, messages failing are dead lettered. The context passed to handle is not cancelled when ctx is done
+	// so the message being handled completes.
+	Consume(ctx context.Context, handle func(ctx context.Context, msg event.Message) error) error
+}
+
+// TaskIndexer defines the datastore indexing tasks.
+type TaskIndexer interface {
+	Index(ctx context.Context, task internal.Task) error
+	Delete(ctx context.Context, id string, version int64) error
+}
+
+// Handler indexes the tasks included in events.
+type Handler struct {
+	task     TaskIndexer
+	retry    retry.Policy
+	source   attribute.KeyValue
+	events   metric.Int64Counter
+	duration metric.Float64Histogram
+	lag      metric.Float64Histogram
+}
+
+// NewHandler instantiates the Handler, source identifies the message broker in the recorded metrics.
+func NewHandler(task TaskIndexer, policy retry.Policy, source string) *Handler {
+	meter := otel.Meter(otelName)
+
+	// Errors report names or views the SDK doesn't accept, the returned instruments record measurements anyway.
+	events, _ := meter.Int64Counter("indexer.events",
+		metric.WithDescription("Number of handled events"))
+	duration, _ := meter.Float64Histogram("indexer.duration",
+		metric.WithDescription("Time spent handling events, including retries, in seconds"),
+		metric.WithUnit("s"))
+	lag, _ := meter.Float64Histogram("indexer.lag",
+		metric.WithDescription("Time between events being published and their handling starting, in seconds"),
+		metric.WithUnit("s"))
+
+	return &Handler{
+		task:     task,
+		retry:    policy,
+		source:   attribute.String("source", source),
+		events:   events,
+		duration: duration,
+		lag:      lag,
+	}
+}
+
+// Handle decodes the event in msg, according to its content type, and indexes the included task retrying failures
+// according to the retry policy. Events that can't be decoded fail with internal.ErrorCodeInvalidArgument.
+func (h *Handler) Handle(ctx context.Context, msg event.Message) error {
+	start := time.Now()
+
+	eventType, err := h.handle(ctx, msg)
+
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+
+	attrs := metric.WithAttributes(h.source, attribute.String("type", eventType), attribute.String("outcome", outcome))
+
+	h.events.Add(ctx, 1, attrs)
+	h.duration.Record(ctx, time.Since(start).Seconds(), attrs)
+
+	return err
+}
+
+func (h *Handler) handle(ctx context.Context, msg event.Message) (_ string, err error) {
+	evt, err := event.Unmarshal(msg)
+	if err != nil {
+		return "unknown", internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "event.Unmarshal")
+	}
+
+	ctx, span := h.startSpan(ctx, msg, evt)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+	}()
+
+	if !evt.Time.IsZero() {
+		h.lag.Record(ctx, time.Since(evt.Time).Seconds(), metric.WithAttributes(h.source, attribute.String("type", evt.Type)))
+	}
+
+	task, err := evt.Task()
+	if err != nil {
+		return evt.Type, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "evt.Task")
+	}
+
+	err = h.retry.Do(ctx, func(ctx context.Context) error {
+		switch evt.Type {
+		case event.TypeTaskUpdated, event.TypeTaskCreated:
+			return h.task.Index(ctx, task)
+		case event.TypeTaskDeleted:
+			return h.task.Delete(ctx, task.ID, task.Version)
+		}
+
+		return internal.NewErrorf(internal.ErrorCodeInvalidArgument, "unknown type %q", evt.Type)
+	})
+	if err != nil {
+		return evt.Type, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "retry.Do")
+	}
+
+	return evt.Type, nil
+}
+
+// startSpan starts the consumer span handling evt using the trace context of the span that published it, read from
+// the message headers or, when the message broker doesn't support them, from the envelope. The span continues the
+// trace of the request that changed the task and links to the publishing span, so backends showing messaging traces
+// separately relate them as well.
+func (h *Handler) startSpan(ctx context.Context, msg event.Message, evt event.Envelope) (context.Context, trace.Span) {
+	propagator := otel.GetTextMapPropagator()
+
+	parent := ctx
+	if msg.Headers != nil {
+		parent = propagator.Extract(ctx, msg.Headers)
+	}
+
+	if !trace.SpanContextFromContext(parent).IsValid() {
+		parent = propagator.Extract(ctx, evt.TraceContext())
+	}
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(h.source.Value.AsString()),
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingMessageID(evt.ID),
+			attribute.String("type", evt.Type),
+		),
+	}
+
+	if sc := trace.SpanContextFromContext(parent); sc.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+	}
+
+	return otel.Tracer(otelName).Start(parent, "Handler.Handle", opts...)
+}
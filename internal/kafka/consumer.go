@@ -0,0 +1,251 @@
+package kafka
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+	"github.com/sanLimbu/todo-api/internal/retry"
+)
+
+const (
+	// commitInterval indicates how often the offsets of handled messages are committed.
+	commitInterval = time.Second
+
+	// workerQueueSize is the number of messages buffered per worker before polling blocks.
+	workerQueueSize = 64
+
+	// revokeTimeout bounds how long revoking partitions waits for the messages being handled, it must be lower than
+	// max.poll.interval.ms, 5 minutes by default, otherwise the consumer leaves the group while waiting.
+	revokeTimeout = 30 * time.Second
+)
+
+// dispatchedMessage is a message queued for a worker, generation identifies the assignment it was consumed in.
+type dispatchedMessage struct {
+	msg        *kafka.Message
+	generation uint64
+}
+
+// Consumer represents the consumer receiving Task events from the topic it is subscribed to. Messages are handled
+// concurrently by a pool of workers, messages with the same key, the task ID, are always handled by the same worker
+// so events of the same task are handled in order.
+type Consumer struct {
+	consumer   *kafka.Consumer
+	deadLetter *DeadLetter
+	workers    int
+	logger     *zap.Logger
+}
+
+// NewConsumer instantiates the Consumer, consumer must be subscribed to the topic already.
+func NewConsumer(consumer *kafka.Consumer, deadLetter *DeadLetter, workers int, logger *zap.Logger) *Consumer {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Consumer{
+		consumer:   consumer,
+		deadLetter: deadLetter,
+		workers:    workers,
+		logger:     logger,
+	}
+}
+
+// Consume calls handle for every message until ctx is done. Messages failing are sent to the dead letter topic,
+// offsets are committed only up to the lowest message not handled or dead lettered yet in each partition. When
+// dead lettering fails the consumer stops and the error is returned, so the message is consumed again after
+// restarting. When partitions are revoked the messages being handled complete before committing, waiting up to
+// revokeTimeout; messages still being handled afterwards are consumed again by the new owner of the partition.
+func (c *Consumer) Consume(ctx context.Context, handle func(ctx context.Context, msg event.Message) error) error {
+	topics, err := c.consumer.Subscription()
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "consumer.Subscription")
+	}
+
+	handleCtx := context.WithoutCancel(ctx)
+	offsets := newOffsetTracker()
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	var (
+		workers  sync.WaitGroup
+		failOnce sync.Once
+		failErr  error
+	)
+
+	failed := make(chan struct{})
+
+	// fail stops consuming, messages already dispatched are not handled so they are consumed again in order.
+	fail := func(err error) {
+		failOnce.Do(func() {
+			failErr = err
+			close(failed)
+			stop()
+		})
+	}
+
+	queues := make([]chan dispatchedMessage, c.workers)
+
+	for i := range queues {
+		queues[i] = make(chan dispatchedMessage, workerQueueSize)
+
+		workers.Add(1)
+
+		go func(queue <-chan dispatchedMessage) {
+			defer workers.Done()
+
+			for dispatched := range queue {
+				select {
+				case <-failed:
+					continue
+				default:
+				}
+
+				if err := c.handle(handleCtx, dispatched.msg, handle); err != nil {
+					fail(err)
+
+					continue
+				}
+
+				offsets.Handled(dispatched.msg.TopicPartition, dispatched.generation)
+			}
+		}(queues[i])
+	}
+
+	commit := func() {
+		if tps := offsets.Commitable(); len(tps) > 0 {
+			if _, err := c.consumer.CommitOffsets(tps); err != nil {
+				c.logger.Error("Commit failed", zap.Error(err))
+			}
+		}
+	}
+
+	// drain waits until the dispatched messages are handled, it returns false when they are not handled in time or
+	// will never be because consuming failed.
+	drain := func() bool {
+		timeout := time.NewTimer(revokeTimeout)
+		defer timeout.Stop()
+
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for offsets.Pending() {
+			select {
+			case <-failed:
+				return false
+			case <-timeout.C:
+				return false
+			case <-ticker.C:
+			}
+		}
+
+		return true
+	}
+
+	// The rebalance callback is called while polling, so no new messages are dispatched while waiting.
+	rebalance := func(consumer *kafka.Consumer, evt kafka.Event) error {
+		if _, ok := evt.(kafka.RevokedPartitions); ok {
+			if !drain() {
+				c.logger.Warn("Revoking partitions before handling all messages, they will be consumed again")
+			}
+
+			if !consumer.AssignmentLost() {
+				commit()
+			}
+
+			offsets.Reset()
+		}
+
+		return nil
+	}
+
+	if err := c.consumer.SubscribeTopics(topics, rebalance); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "consumer.SubscribeTopics")
+	}
+
+	lastCommit := time.Now()
+
+	for ctx.Err() == nil {
+		if time.Since(lastCommit) >= commitInterval {
+			commit()
+
+			lastCommit = time.Now()
+		}
+
+		switch evt := c.consumer.Poll(150).(type) {
+		case *kafka.Message:
+			generation := offsets.Dispatched(evt.TopicPartition)
+
+			queues[c.worker(evt)] <- dispatchedMessage{msg: evt, generation: generation}
+		case kafka.Error:
+			if evt.IsFatal() {
+				err = internal.WrapErrorf(evt, internal.ErrorCodeUnkown, "consumer.Poll")
+			}
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	for _, queue := range queues {
+		close(queue)
+	}
+
+	workers.Wait()
+	commit()
+
+	if failErr != nil {
+		return internal.WrapErrorf(failErr, internal.ErrorCodeUnkown, "handle")
+	}
+
+	return err
+}
+
+// handle returns an error when the message was neither handled nor dead lettered, its offset must not be committed
+// so it is consumed again after restarting.
+func (c *Consumer) handle(ctx context.Context, msg *kafka.Message, handle func(ctx context.Context, msg event.Message) error) error {
+	var contentType string
+
+	for _, h := range msg.Headers {
+		if h.Key == headerContentType {
+			contentType = string(h.Value)
+		}
+	}
+
+	reason := handle(ctx, event.Message{ContentType: contentType, Headers: headerCarrier{msg: msg}, Body: msg.Value})
+	if reason == nil {
+		return nil
+	}
+
+	c.logger.Error("Couldn't consume, dead lettering", zap.Error(reason))
+
+	err := retry.NewPolicy().Do(ctx, func(ctx context.Context) error {
+		return c.deadLetter.Send(ctx, msg, reason)
+	})
+	if err != nil {
+		c.logger.Error("Couldn't dead letter", zap.Error(err), zap.String("partition", msg.TopicPartition.String()))
+
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "deadLetter.Send")
+	}
+
+	return nil
+}
+
+// worker returns the worker handling msg, messages without a key are distributed by partition.
+func (c *Consumer) worker(msg *kafka.Message) int {
+	if len(msg.Key) == 0 {
+		return int(msg.TopicPartition.Partition) % c.workers
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(msg.Key)
+
+	return int(h.Sum32() % uint32(c.workers))
+}
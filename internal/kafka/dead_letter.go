@@ -0,0 +1,172 @@
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+)
+
+const (
+	headerDeadLetterError     = "dead-letter-error"
+	headerDeadLetterFailedAt  = "dead-letter-failed-at"
+	headerDeadLetterTopic     = "dead-letter-topic"
+	headerDeadLetterPartition = "dead-letter-partition"
+	headerDeadLetterOffset    = "dead-letter-offset"
+
+	headerDeadLetterPrefix = "dead-letter-"
+)
+
+// DeadLetter represents the topic holding messages that couldn't be processed by consumers.
+type DeadLetter struct {
+	producer *kafka.Producer
+	consumer *kafka.Consumer
+	topic    string
+}
+
+// NewDeadLetter instantiates the DeadLetter repository, consumer must be subscribed to topic and it is only required
+// for listing and replaying dead letters.
+func NewDeadLetter(producer *kafka.Producer, consumer *kafka.Consumer, topic string) *DeadLetter {
+	return &DeadLetter{
+		producer: producer,
+		consumer: consumer,
+		topic:    topic,
+	}
+}
+
+// Send publishes msg to the dead letter topic including the reason it failed, it waits for the broker to
+// acknowledge it so the original message can be safely committed afterwards.
+func (d *DeadLetter) Send(ctx context.Context, msg *kafka.Message, reason error) error {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+5)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: headerDeadLetterError, Value: []byte(reason.Error())},
+		kafka.Header{Key: headerDeadLetterFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+		kafka.Header{Key: headerDeadLetterTopic, Value: []byte(*msg.TopicPartition.Topic)},
+		kafka.Header{Key: headerDeadLetterPartition, Value: []byte(strconv.Itoa(int(msg.TopicPartition.Partition)))},
+		kafka.Header{Key: headerDeadLetterOffset, Value: []byte(msg.TopicPartition.Offset.String())},
+	)
+
+	if err := produce(ctx, d.producer, &kafka.Message{
+		TopicPartition: kafka.TopicPartition{
+			Topic:     &d.topic,
+			Partition: kafka.PartitionAny,
+		},
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "produce")
+	}
+
+	return nil
+}
+
+// List returns up to limit dead letters without removing them, it stops after waiting idle for new messages.
+func (d *DeadLetter) List(ctx context.Context, limit int, idle time.Duration) ([]event.DeadLetter, error) {
+	msgs, err := d.read(ctx, limit, idle)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "read")
+	}
+
+	res := make([]event.DeadLetter, len(msgs))
+	for i, msg := range msgs {
+		res[i] = newDeadLetter(msg)
+	}
+
+	return res, nil
+}
+
+// Replay publishes up to limit dead letters back to their original topic, replayed dead letters are committed so
+// they are not replayed again.
+func (d *DeadLetter) Replay(ctx context.Context, limit int, idle time.Duration) (int, error) {
+	msgs, err := d.read(ctx, limit, idle)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "read")
+	}
+
+	for i, msg := range msgs {
+		dl := newDeadLetter(msg)
+
+		headers := make([]kafka.Header, 0, len(msg.Headers))
+
+		for _, h := range msg.Headers {
+			if !strings.HasPrefix(h.Key, headerDeadLetterPrefix) {
+				headers = append(headers, h)
+			}
+		}
+
+		if err := produce(ctx, d.producer, &kafka.Message{
+			TopicPartition: kafka.TopicPartition{
+				Topic:     &dl.Destination,
+				Partition: kafka.PartitionAny,
+			},
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: headers,
+		}); err != nil {
+			return i, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "produce")
+		}
+
+		if _, err := d.consumer.CommitMessage(msg); err != nil {
+			return i, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "consumer.CommitMessage")
+		}
+	}
+
+	return len(msgs), nil
+}
+
+func (d *DeadLetter) read(ctx context.Context, limit int, idle time.Duration) ([]*kafka.Message, error) {
+	if d.consumer == nil {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "consumer is required")
+	}
+
+	var res []*kafka.Message
+
+	last := time.Now()
+
+	for len(res) < limit && time.Since(last) < idle {
+		if err := ctx.Err(); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "ctx.Err")
+		}
+
+		switch evt := d.consumer.Poll(150).(type) {
+		case *kafka.Message:
+			res = append(res, evt)
+			last = time.Now()
+		case kafka.Error:
+			if evt.IsFatal() {
+				return nil, internal.WrapErrorf(evt, internal.ErrorCodeUnkown, "consumer.Poll")
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func newDeadLetter(msg *kafka.Message) event.DeadLetter {
+	res := event.DeadLetter{
+		ID:   msg.TopicPartition.String(),
+		Body: msg.Value,
+	}
+
+	for _, h := range msg.Headers {
+		switch h.Key {
+		case headerDeadLetterError:
+			res.Error = string(h.Value)
+		case headerDeadLetterTopic:
+			res.Destination = string(h.Value)
+		case headerDeadLetterFailedAt:
+			res.FailedAt, _ = time.Parse(time.RFC3339Nano, string(h.Value))
+		case headerContentType:
+			res.ContentType = string(h.Value)
+		}
+	}
+
+	return res
+}
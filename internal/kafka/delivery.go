@@ -0,0 +1,74 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// deliveries counts the delivery reports received from the brokers.
+var deliveries, _ = otel.Meter(otelName).Int64Counter("kafka.producer.deliveries",
+	metric.WithDescription("Number of delivery reports received by outcome"))
+
+// ReportDeliveries handles the delivery reports of messages published without waiting for them, failures are
+// logged. It blocks until the producer is closed.
+func ReportDeliveries(producer *kafka.Producer, logger *zap.Logger) {
+	for evt := range producer.Events() {
+		switch e := evt.(type) {
+		case *kafka.Message:
+			if err := recordDelivery(e); err != nil {
+				logger.Error("Message not delivered",
+					zap.Error(err),
+					zap.String("key", string(e.Key)))
+			}
+		case kafka.Error:
+			logger.Error("Producer error", zap.Error(e))
+		}
+	}
+}
+
+// produce publishes msg and waits for its delivery report.
+func produce(ctx context.Context, producer *kafka.Producer, msg *kafka.Message) error {
+	deliveryC := make(chan kafka.Event, 1)
+
+	if err := producer.Produce(msg, deliveryC); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "producer.Produce")
+	}
+
+	select {
+	case <-ctx.Done():
+		return internal.WrapErrorf(ctx.Err(), internal.ErrorCodeUnkown, "ctx.Done")
+	case evt := <-deliveryC:
+		if m, ok := evt.(*kafka.Message); ok {
+			if err := recordDelivery(m); err != nil {
+				return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "delivery")
+			}
+		}
+	}
+
+	return nil
+}
+
+func recordDelivery(msg *kafka.Message) error {
+	outcome := "success"
+	if msg.TopicPartition.Error != nil {
+		outcome = "failure"
+	}
+
+	var topic string
+	if msg.TopicPartition.Topic != nil {
+		topic = *msg.TopicPartition.Topic
+	}
+
+	deliveries.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("topic", topic),
+		attribute.String("outcome", outcome)))
+
+	return msg.TopicPartition.Error
+}
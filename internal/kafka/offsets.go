@@ -0,0 +1,135 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+// partitionOffsets tracks the messages of a partition being handled.
+type partitionOffsets struct {
+	pending []kafka.Offset // dispatched but not handled, in increasing order.
+	handled map[kafka.Offset]struct{}
+	next    kafka.Offset // next offset to consume, the one to commit.
+	dirty   bool
+}
+
+// offsetTracker tracks the offsets of messages handled concurrently, a partition is committed only up to the lowest
+// offset still being handled so messages are never skipped after a restart or rebalance.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[partitionKey]*partitionOffsets
+	generation uint64 // incremented by Reset, messages dispatched before are ignored when handled.
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		partitions: make(map[partitionKey]*partitionOffsets),
+	}
+}
+
+// Dispatched indicates the message is about to be handled, it must be called in the same order messages are
+// received. It returns the generation of the assignment the message belongs to, it is passed to Handled.
+func (o *offsetTracker) Dispatched(tp kafka.TopicPartition) uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	key := partitionKey{topic: *tp.Topic, partition: tp.Partition}
+
+	p, ok := o.partitions[key]
+	if !ok {
+		p = &partitionOffsets{handled: make(map[kafka.Offset]struct{})}
+		o.partitions[key] = p
+	}
+
+	p.pending = append(p.pending, tp.Offset)
+
+	return o.generation
+}
+
+// Handled indicates the message was handled, its offset can be committed once all previous messages are handled.
+func (o *offsetTracker) Handled(tp kafka.TopicPartition, generation uint64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Messages of partitions revoked before they were handled may complete after the partition is assigned again,
+	// the same offsets may be pending again but they are handled by the new assignment.
+	if generation != o.generation {
+		return
+	}
+
+	p, ok := o.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]
+	if !ok || len(p.pending) == 0 || tp.Offset < p.pending[0] {
+		return
+	}
+
+	p.handled[tp.Offset] = struct{}{}
+
+	for len(p.pending) > 0 {
+		offset := p.pending[0]
+
+		if _, ok := p.handled[offset]; !ok {
+			break
+		}
+
+		delete(p.handled, offset)
+
+		p.pending = p.pending[1:]
+		p.next = offset + 1
+		p.dirty = true
+	}
+}
+
+// Pending indicates whether any dispatched message is not handled yet.
+func (o *offsetTracker) Pending() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, p := range o.partitions {
+		if len(p.pending) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Commitable returns the offsets that changed since the last call.
+func (o *offsetTracker) Commitable() []kafka.TopicPartition {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var res []kafka.TopicPartition
+
+	for key, p := range o.partitions {
+		if !p.dirty {
+			continue
+		}
+
+		topic := key.topic
+
+		res = append(res, kafka.TopicPartition{
+			Topic:     &topic,
+			Partition: key.partition,
+			Offset:    p.next,
+		})
+
+		p.dirty = false
+	}
+
+	return res
+}
+
+// Reset forgets all partitions and starts a new generation, used after partitions are revoked.
+func (o *offsetTracker) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.partitions = make(map[partitionKey]*partitionOffsets)
+	o.generation++
+}
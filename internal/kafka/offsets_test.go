@@ -0,0 +1,107 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	t.Parallel()
+
+	topic := "tasks"
+
+	tp := func(offset kafka.Offset) kafka.TopicPartition {
+		return kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: offset}
+	}
+
+	assertCommitable := func(t *testing.T, offsets *offsetTracker, expected ...kafka.Offset) {
+		t.Helper()
+
+		tps := offsets.Commitable()
+
+		if len(tps) != len(expected) {
+			t.Fatalf("expected %d commitable offsets, got %v", len(expected), tps)
+		}
+
+		for i, tp := range tps {
+			if tp.Offset != expected[i] {
+				t.Fatalf("expected offset %d, got %d", expected[i], tp.Offset)
+			}
+		}
+	}
+
+	t.Run("Out of order", func(t *testing.T) {
+		t.Parallel()
+
+		offsets := newOffsetTracker()
+
+		gen := offsets.Dispatched(tp(10))
+		offsets.Dispatched(tp(11))
+		offsets.Dispatched(tp(12))
+
+		offsets.Handled(tp(11), gen)
+		assertCommitable(t, offsets)
+
+		offsets.Handled(tp(10), gen)
+		assertCommitable(t, offsets, 12)
+
+		if !offsets.Pending() {
+			t.Fatalf("expected offset 12 to be pending")
+		}
+
+		offsets.Handled(tp(12), gen)
+		assertCommitable(t, offsets, 13)
+
+		if offsets.Pending() {
+			t.Fatalf("expected no pending offsets")
+		}
+	})
+
+	t.Run("Stale after reset", func(t *testing.T) {
+		t.Parallel()
+
+		offsets := newOffsetTracker()
+
+		stale := offsets.Dispatched(tp(10))
+		offsets.Dispatched(tp(11))
+		offsets.Reset()
+
+		// The partition is assigned again starting after the committed offset.
+		gen := offsets.Dispatched(tp(12))
+
+		offsets.Handled(tp(10), stale)
+		offsets.Handled(tp(11), stale)
+		assertCommitable(t, offsets)
+
+		offsets.Handled(tp(12), gen)
+		assertCommitable(t, offsets, 13)
+	})
+
+	t.Run("Same offsets after reset", func(t *testing.T) {
+		t.Parallel()
+
+		offsets := newOffsetTracker()
+
+		stale := offsets.Dispatched(tp(10))
+		offsets.Dispatched(tp(11))
+		offsets.Reset()
+
+		// The partition is assigned again starting at the same offsets because they were not committed.
+		gen := offsets.Dispatched(tp(10))
+		offsets.Dispatched(tp(11))
+
+		// Messages of the previous assignment completing late don't commit the messages of the new one.
+		offsets.Handled(tp(10), stale)
+		offsets.Handled(tp(11), stale)
+		assertCommitable(t, offsets)
+
+		if !offsets.Pending() {
+			t.Fatalf("expected the offsets of the new assignment to be pending")
+		}
+
+		offsets.Handled(tp(10), gen)
+		offsets.Handled(tp(11), gen)
+		assertCommitable(t, offsets, 12)
+	})
+}
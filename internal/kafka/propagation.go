@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+// headerCarrier adapts the headers of a message so the trace context is injected into and extracted from them.
+type headerCarrier struct {
+	msg *kafka.Message
+}
+
+// Get returns the value of the last header named key.
+func (c headerCarrier) Get(key string) string {
+	var res string
+
+	for _, h := range c.msg.Headers {
+		if h.Key == key {
+			res = string(h.Value)
+		}
+	}
+
+	return res
+}
+
+// Set replaces the headers named key.
+func (c headerCarrier) Set(key, value string) {
+	headers := c.msg.Headers[:0]
+
+	for _, h := range c.msg.Headers {
+		if h.Key != key {
+			headers = append(headers, h)
+		}
+	}
+
+	c.msg.Headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+// Keys returns the names of the headers.
+func (c headerCarrier) Keys() []string {
+	res := make([]string, 0, len(c.msg.Headers))
+
+	for _, h := range c.msg.Headers {
+		res = append(res, h.Key)
+	}
+
+	return res
+}
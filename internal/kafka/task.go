@@ -1,55 +1,60 @@
 package kafka
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.16.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
 )
 
-type Task struct {
-	producer  *kafka.Producer
-	topicName string
-}
+const otelName = "github.com/sanLimbu/todo-api/internal/kafka"
 
-type event struct {
-	Type  string
-	Value internal.Task
+const headerContentType = "content-type"
+
+//Task represents the repository used for publishing Task records.
+type Task struct {
+	producer   *kafka.Producer
+	serializer event.Serializer
+	topicName  string
+	sync       bool
 }
 
-//NewTask instantiates the Task repository
-func NewTask(producer *kafka.Producer, topicName string) *Task {
+//NewTask instantiates the Task repository. When sync is true publishing waits for the brokers to acknowledge the
+//message, otherwise delivery reports are received by ReportDeliveries.
+func NewTask(producer *kafka.Producer, serializer event.Serializer, topicName string, sync bool) *Task {
 	return &Task{
-		topicName: topicName,
-		producer:  producer,
+		topicName:  topicName,
+		producer:   producer,
+		serializer: serializer,
+		sync:       sync,
 	}
 }
 
 //Created publishes a message indicating a task was created
 func (t *Task) Created(ctx context.Context, task internal.Task) error {
-	return t.pubish(ctx, "Task.Created", "Task.event.created", task)
+	return t.pubish(ctx, "Task.Created", event.TypeTaskCreated, task)
 }
 
 //Deleted publishes a message indicating a task was deleted
-func (t *Task) Deleted(ctx context.Context, id string) error {
-	return t.pubish(ctx, "Task.Deleted", "tasks.event.deleted", internal.Task{ID: id})
+func (t *Task) Deleted(ctx context.Context, id string, version int64) error {
+	return t.pubish(ctx, "Task.Deleted", event.TypeTaskDeleted, internal.Task{ID: id, Version: version})
 }
 
 //Updated publishes a message indicating a task was updated.
 func (t *Task) Updated(ctx context.Context, task internal.Task) error {
-	return t.pubish(ctx, "Task.Updated", "tasks.event.updated", task)
+	return t.pubish(ctx, "Task.Updated", event.TypeTaskUpdated, task)
 }
 
 func (t *Task) pubish(ctx context.Context, spanName, msgType string, task internal.Task) error {
 
 	tracer := otel.Tracer("kafka")
-	ctx, span := tracer.Start(ctx, spanName)
+	ctx, span := tracer.Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindProducer))
 
 	//ctx, span := trace.SpanFromContext(ctx).Tracer().Start(ctx, spanName)
 	defer span.End()
@@ -60,23 +65,35 @@ func (t *Task) pubish(ctx context.Context, spanName, msgType string, task intern
 			Value: attribute.StringValue("kafka"),
 		},
 	)
-	var b bytes.Buffer
-	evt := event{
-		Type:  msgType,
-		Value: task,
+	b, err := t.serializer.Marshal(event.NewTask(ctx, msgType, task))
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "serializer.Marshal")
 	}
 
-	if err := json.NewEncoder(&b).Encode(evt); err != nil {
-		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Encode")
-	}
-
-	if err := t.producer.Produce(&kafka.Message{
+	msg := &kafka.Message{
 		TopicPartition: kafka.TopicPartition{
 			Topic:     &t.topicName,
 			Partition: kafka.PartitionAny,
 		},
-		Value: b.Bytes(),
-	}, nil); err != nil {
+		// Keying by task ID sends all events of the same task to the same partition, keeping them in order.
+		Key:   []byte(task.ID),
+		Value: b,
+		Headers: []kafka.Header{
+			{Key: headerContentType, Value: []byte(t.serializer.ContentType())},
+		},
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{msg: msg})
+
+	if t.sync {
+		if err := produce(ctx, t.producer, msg); err != nil {
+			return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "produce")
+		}
+
+		return nil
+	}
+
+	if err := t.producer.Produce(msg, nil); err != nil {
 		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "product.Producer")
 	}
 	return nil
@@ -1,13 +1,13 @@
 package memcached
 
 import (
-	"bytes"
 	"context"
-	"encoding/gob"
+	"errors"
 	"time"
 
 	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/cache"
 	"go.opentelemetry.io/otel"
 	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
 	"go.opentelemetry.io/otel/trace"
@@ -15,45 +15,99 @@ import (
 
 const otelName = "github.com/sanLimbu/todo-api/internal/memcached"
 
-func deleteTask(ctx context.Context, client *memcache.Client, key string) {
+// Cache represents the cache backend storing values in memcached.
+type Cache struct {
+	client *memcache.Client
+}
 
-	defer newOTELSpan(ctx, "deleteTask").End()
+// NewCache instantiates the memcached Cache.
+func NewCache(client *memcache.Client) *Cache {
+	return &Cache{
+		client: client,
+	}
+}
 
-	_ = client.Delete(key)
+// Get returns the cached value.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	defer newOTELSpan(ctx, "Cache.Get").End()
 
-}
+	item, err := c.client.Get(key)
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return nil, cache.ErrMiss
+		}
 
-func getTask(ctx context.Context, client *memcache.Client, key string, target interface{}) error {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Get")
+	}
 
-	defer newOTELSpan(ctx, "getTask").End()
+	return item.Value, nil
+}
 
-	item, err := client.Get(key)
-	if err != nil {
-		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Get")
+// Set caches the value.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	defer newOTELSpan(ctx, "Cache.Set").End()
+
+	if err := c.client.Set(newItem(key, value, expiration)); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Set")
 	}
 
-	if err := gob.NewDecoder(bytes.NewReader(item.Value)).Decode(target); err != nil {
-		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "gob.NewDecoder")
+	return nil
+}
+
+// Add caches the value when key is not cached.
+func (c *Cache) Add(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	defer newOTELSpan(ctx, "Cache.Add").End()
+
+	if err := c.client.Add(newItem(key, value, expiration)); err != nil {
+		if errors.Is(err, memcache.ErrNotStored) {
+			return cache.ErrNotStored
+		}
+
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Add")
 	}
 
 	return nil
+}
+
+// Increment adds delta to the cached number.
+func (c *Cache) Increment(ctx context.Context, key string, delta uint64) (uint64, error) {
+	defer newOTELSpan(ctx, "Cache.Increment").End()
+
+	n, err := c.client.Increment(key, delta)
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return 0, cache.ErrMiss
+		}
+
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Increment")
+	}
 
+	return n, nil
 }
 
-func setTask(ctx context.Context, client *memcache.Client, key string, value interface{}, expiration time.Duration) {
-	defer newOTELSpan(ctx, "setTask").End()
+// Delete removes the key.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	defer newOTELSpan(ctx, "Cache.Delete").End()
 
-	var b bytes.Buffer
+	if err := c.client.Delete(key); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Delete")
+	}
+
+	return nil
+}
+
+// newItem uses an absolute expiration time because memcached treats relative ones longer than 30 days as timestamps.
+func newItem(key string, value []byte, expiration time.Duration) *memcache.Item {
+	item := memcache.Item{
+		Key:   key,
+		Value: value,
+	}
 
-	if err := gob.NewEncoder(&b).Encode(value); err != nil {
-		return
+	if expiration > 0 {
+		item.Expiration = int32(time.Now().Add(expiration).Unix())
 	}
 
-	_ = client.Set(&memcache.Item{
-		Key:        key,
-		Value:      b.Bytes(),
-		Expiration: int32(time.Now().Add(expiration).Unix()),
-	})
+	return &item
 }
 
 func newOTELSpan(ctx context.Context, name string) trace.Span {
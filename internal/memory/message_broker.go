@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+)
+
+const (
+	// EventTypeTaskCreated indicates a task was created.
+	EventTypeTaskCreated = event.TypeTaskCreated
+
+	// EventTypeTaskUpdated indicates a task was updated.
+	EventTypeTaskUpdated = event.TypeTaskUpdated
+
+	// EventTypeTaskDeleted indicates a task was deleted, only the ID and the version of the deletion are set in the task.
+	EventTypeTaskDeleted = event.TypeTaskDeleted
+)
+
+// Event represents a message published to the in-process event bus, events are not encoded because they never
+// leave the process.
+type Event struct {
+	Type string
+	Task internal.Task
+}
+
+// EventHandler handles events published to the in-process event bus.
+type EventHandler func(ctx context.Context, evt Event) error
+
+// TaskMessageBroker represents the repository used for publishing Task events to subscribers in the same process.
+// Events are delivered synchronously in the same order the subscribers were added.
+type TaskMessageBroker struct {
+	mu       sync.RWMutex
+	handlers []EventHandler
+}
+
+// NewTaskMessageBroker instantiates the TaskMessageBroker repository.
+func NewTaskMessageBroker() *TaskMessageBroker {
+	return &TaskMessageBroker{}
+}
+
+// Subscribe adds a handler that receives all events published after calling it.
+func (t *TaskMessageBroker) Subscribe(h EventHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handlers = append(t.handlers, h)
+}
+
+// Created publishes a message indicating a task was created.
+func (t *TaskMessageBroker) Created(ctx context.Context, task internal.Task) error {
+	return t.publish(ctx, Event{Type: EventTypeTaskCreated, Task: task})
+}
+
+// Deleted publishes a message indicating a task was deleted.
+func (t *TaskMessageBroker) Deleted(ctx context.Context, id string, version int64) error {
+	return t.publish(ctx, Event{Type: EventTypeTaskDeleted, Task: internal.Task{ID: id, Version: version}})
+}
+
+// Updated publishes a message indicating a task was updated.
+func (t *TaskMessageBroker) Updated(ctx context.Context, task internal.Task) error {
+	return t.publish(ctx, Event{Type: EventTypeTaskUpdated, Task: task})
+}
+
+func (t *TaskMessageBroker) publish(ctx context.Context, evt Event) error {
+	t.mu.RLock()
+	handlers := t.handlers
+	t.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := h(ctx, evt); err != nil {
+			return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "handler")
+		}
+	}
+
+	return nil
+}
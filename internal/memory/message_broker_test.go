@@ -0,0 +1,39 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/memory"
+	"github.com/sanLimbu/todo-api/internal/service/servicetesting"
+)
+
+func TestTaskMessageBroker(t *testing.T) {
+	t.Parallel()
+
+	servicetesting.TaskMessageBrokerRepository(t, func(_ *testing.T) servicetesting.BrokerFixture {
+		broker := memory.NewTaskMessageBroker()
+
+		// Events are delivered synchronously, the buffer keeps publishing from blocking until Next is called.
+		events := make(chan memory.Event, 10)
+
+		broker.Subscribe(func(_ context.Context, evt memory.Event) error {
+			events <- evt
+
+			return nil
+		})
+
+		return servicetesting.BrokerFixture{
+			Broker: broker,
+			Next: func(ctx context.Context) (string, internal.Task, error) {
+				select {
+				case evt := <-events:
+					return evt.Type, evt.Task, nil
+				case <-ctx.Done():
+					return "", internal.Task{}, ctx.Err()
+				}
+			},
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// SearchableTask represents the in-memory repository used for searching Task records.
+type SearchableTask struct {
+	mu    sync.RWMutex
+	tasks map[string]internal.Task
+}
+
+// NewSearchableTask instantiates the SearchableTask repository.
+func NewSearchableTask() *SearchableTask {
+	return &SearchableTask{
+		tasks: make(map[string]internal.Task),
+	}
+}
+
+// Index creates or updates a searchable task.
+func (t *SearchableTask) Index(_ context.Context, task internal.Task) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tasks[task.ID] = task
+
+	return nil
+}
+
+// Delete removes a searchable task, versions are not compared because in-process events are delivered in order.
+func (t *SearchableTask) Delete(_ context.Context, id string, _ int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.tasks, id)
+
+	return nil
+}
+
+// Search returns tasks matching any of the received values, the description is matched as a case insensitive
+// substring. Results are sorted by the number of matched values and then by id, the same way the Elasticsearch
+// repository does.
+func (t *SearchableTask) Search(_ context.Context, args internal.SearchParams) (internal.SearchResults, error) {
+	if args.IsZero() {
+		return internal.SearchResults{}, nil
+	}
+
+	from := args.From
+
+	if args.Cursor != "" {
+		var err error
+
+		from, err = decodeSearchCursor(args.Cursor, newQueryKey(args))
+		if err != nil {
+			return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "decodeSearchCursor")
+		}
+	}
+
+	type match struct {
+		task  internal.Task
+		score int
+	}
+
+	t.mu.RLock()
+
+	matches := make([]match, 0, len(t.tasks))
+
+	for _, task := range t.tasks {
+		var score int
+
+		if args.Description != nil && *args.Description != "" &&
+			strings.Contains(strings.ToLower(task.Description), strings.ToLower(*args.Description)) {
+			score++
+		}
+
+		if args.Priority != nil && task.Priority == *args.Priority {
+			score++
+		}
+
+		if args.IsDone != nil && task.IsDone == *args.IsDone {
+			score++
+		}
+
+		if score > 0 {
+			matches = append(matches, match{task: task, score: score})
+		}
+	}
+
+	t.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+
+		return matches[i].task.ID < matches[j].task.ID
+	})
+
+	total := int64(len(matches))
+
+	// Negative values are rejected by the handlers, they are clamped so slicing never panics.
+	start := min(max(from, 0), total)
+	end := min(start+max(args.Size, 0), total)
+
+	res := make([]internal.Task, 0, end-start)
+	for _, m := range matches[start:end] {
+		res = append(res, m.task)
+	}
+
+	var nextCursor string
+
+	if count := int64(len(res)); count > 0 && count == args.Size && end < total {
+		nextCursor = encodeSearchCursor(end, newQueryKey(args))
+	}
+
+	return internal.SearchResults{
+		Task:       res,
+		Total:      total,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// encodeSearchCursor returns the cursor of the page starting at from, query identifies the search so the cursor is
+// not used with other arguments.
+func encodeSearchCursor(from int64, query string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(from, 10) + "\x00" + query))
+}
+
+func decodeSearchCursor(v, query string) (int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "base64.DecodeString")
+	}
+
+	fromStr, cursorQuery, _ := strings.Cut(string(b), "\x00")
+
+	res, err := strconv.ParseInt(fromStr, 10, 64)
+	if err != nil || res < 0 {
+		return 0, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "invalid cursor")
+	}
+
+	if cursorQuery != query {
+		return 0, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "cursor belongs to a different search")
+	}
+
+	return res, nil
+}
+
+// newQueryKey returns the key identifying the values searched, size is not included so pages can have different
+// sizes.
+func newQueryKey(args internal.SearchParams) string {
+	parts := make([]string, 3)
+
+	if args.Description != nil {
+		parts[0] = strconv.Quote(*args.Description)
+	}
+
+	if args.Priority != nil {
+		parts[1] = args.Priority.String()
+	}
+
+	if args.IsDone != nil {
+		parts[2] = strconv.FormatBool(*args.IsDone)
+	}
+
+	return strings.Join(parts, ",")
+}
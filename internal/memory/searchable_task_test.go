@@ -0,0 +1,62 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/memory"
+	"github.com/sanLimbu/todo-api/internal/service/servicetesting"
+)
+
+func TestSearchableTask(t *testing.T) {
+	t.Parallel()
+
+	servicetesting.TaskSearchRepository(t, func(_ *testing.T) servicetesting.SearchFixture {
+		repo := memory.NewSearchableTask()
+
+		return servicetesting.SearchFixture{
+			Search: repo,
+			Store: func(ctx context.Context, task internal.Task) (internal.Task, error) {
+				task.ID = uuid.NewString()
+
+				return task, repo.Index(ctx, task)
+			},
+			Remove: func(ctx context.Context, id string) error {
+				return repo.Delete(ctx, id, 0)
+			},
+		}
+	})
+}
+
+func TestSearchableTask_NegativeValues(t *testing.T) {
+	t.Parallel()
+
+	repo := memory.NewSearchableTask()
+
+	if err := repo.Index(context.Background(), internal.Task{ID: uuid.NewString(), Description: "buy milk"}); err != nil {
+		t.Fatalf("Index failed: %s", err)
+	}
+
+	description := "milk"
+
+	res, err := repo.Search(context.Background(), internal.SearchParams{Description: &description, From: -1, Size: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %s", err)
+	}
+
+	if len(res.Task) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(res.Task))
+	}
+
+	res, err = repo.Search(context.Background(), internal.SearchParams{Description: &description, Size: -1})
+	if err != nil {
+		t.Fatalf("Search failed: %s", err)
+	}
+
+	if len(res.Task) != 0 || res.NextCursor != "" {
+		t.Fatalf("expected no tasks and no cursor, got %d tasks and %q", len(res.Task), res.NextCursor)
+	}
+}
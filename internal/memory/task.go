@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// Task represents the in-memory repository used for interacting with Task records.
+type Task struct {
+	mu    sync.RWMutex
+	tasks map[string]internal.Task
+}
+
+// NewTask instantiates the Task repository.
+func NewTask() *Task {
+	return &Task{
+		tasks: make(map[string]internal.Task),
+	}
+}
+
+// Create inserts a new task record.
+func (t *Task) Create(_ context.Context, params internal.CreateParams) (internal.Task, error) {
+	task := internal.Task{
+		ID:          uuid.NewString(),
+		Description: params.Description,
+		Priority:    params.Priority,
+		Dates:       params.Dates,
+		Version:     1,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tasks[task.ID] = task
+
+	return task, nil
+}
+
+// Delete deletes the existing record matching the id, the version of the deletion follows the last version of the
+// record.
+func (t *Task) Delete(_ context.Context, id string) (int64, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	task, ok := t.tasks[id]
+	if !ok {
+		return 0, internal.NewErrorf(internal.ErrorCodeNotFound, "task not found")
+	}
+
+	delete(t.tasks, id)
+
+	return task.Version + 1, nil
+}
+
+// Find returns the requested task by searching its id.
+func (t *Task) Find(_ context.Context, id string) (internal.Task, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	task, ok := t.tasks[id]
+	if !ok {
+		return internal.Task{}, internal.NewErrorf(internal.ErrorCodeNotFound, "task not found")
+	}
+
+	return task, nil
+}
+
+// Update updates the existing record with new values.
+func (t *Task) Update(_ context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) (internal.Task, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	task, ok := t.tasks[id]
+	if !ok {
+		return internal.Task{}, internal.NewErrorf(internal.ErrorCodeNotFound, "task not found")
+	}
+
+	res := internal.Task{
+		ID:          id,
+		Description: description,
+		Priority:    priority,
+		Dates:       dates,
+		IsDone:      isDone,
+		Version:     task.Version + 1,
+	}
+
+	t.tasks[id] = res
+
+	return res, nil
+}
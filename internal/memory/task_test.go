@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/sanLimbu/todo-api/internal/memory"
+	"github.com/sanLimbu/todo-api/internal/service"
+	"github.com/sanLimbu/todo-api/internal/service/servicetesting"
+)
+
+func TestTask(t *testing.T) {
+	t.Parallel()
+
+	servicetesting.TaskRepository(t, func(_ *testing.T) service.TaskRepository {
+		return memory.NewTask()
+	})
+}
@@ -31,13 +31,15 @@ func (c CreateParams) Validate() error {
 	return nil
 }
 
-//SearchParams defines the arguments used for searching Task records.
+//SearchParams defines the arguments used for searching Task records. When Cursor is set From is ignored and
+//results continue right after the last Task returned by the previous page.
 type SearchParams struct {
 	Description *string
 	Priority    *Priority
 	IsDone      *bool
 	From        int64
 	Size        int64
+	Cursor      string
 }
 
 //IsZero defines whether the search arguments have values or not.
@@ -45,8 +47,10 @@ func (a SearchParams) IsZero() bool {
 	return a.Description == nil && a.Priority == nil && a.IsDone == nil
 }
 
-//SearchResults defines the collection of tasks that were found.
+//SearchResults defines the collection of tasks that were found, NextCursor is used for requesting the next
+//page and is empty when there are no more results.
 type SearchResults struct {
-	Task  []Task
-	Total int64
+	Task       []Task
+	Total      int64
+	NextCursor string
 }
@@ -56,11 +56,30 @@ func (ns NullPriority) Value() (driver.Value, error) {
 	return string(ns.Priority), nil
 }
 
+type TaskEvents struct {
+	Position      int64
+	TaskID        uuid.UUID
+	Version       int64
+	Type          string
+	Data          []byte
+	OccurredAt    pgtype.Timestamptz
+	TransactionID int64
+}
+
+type TaskSnapshots struct {
+	TaskID     uuid.UUID
+	Version    int64
+	Data       []byte
+	OccurredAt pgtype.Timestamptz
+}
+
 type Tasks struct {
-	ID          uuid.UUID
-	Description string
-	Priority    Priority
-	StartDate   pgtype.Timestamp
-	DueDate     pgtype.Timestamp
-	Done        bool
+	ID             uuid.UUID
+	Description    string
+	Priority       Priority
+	StartDate      pgtype.Timestamp
+	DueDate        pgtype.Timestamp
+	Done           bool
+	DescriptionTsv interface{}
+	Version        int64
 }
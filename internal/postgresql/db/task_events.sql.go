@@ -0,0 +1,373 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: task_events.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const CountTasksWithoutEvents = `-- name: CountTasksWithoutEvents :one
+SELECT
+  COUNT(*)
+FROM
+  tasks
+WHERE
+  NOT EXISTS (SELECT 1 FROM task_events WHERE task_events.task_id = tasks.id)
+`
+
+func (q *Queries) CountTasksWithoutEvents(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, CountTasksWithoutEvents)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const DeleteTaskProjection = `-- name: DeleteTaskProjection :exec
+DELETE FROM
+  tasks
+WHERE
+  id = $1
+`
+
+func (q *Queries) DeleteTaskProjection(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, DeleteTaskProjection, id)
+	return err
+}
+
+const InsertTaskEvent = `-- name: InsertTaskEvent :one
+INSERT INTO task_events (
+  task_id,
+  version,
+  type,
+  data,
+  occurred_at
+)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5
+)
+RETURNING position
+`
+
+type InsertTaskEventParams struct {
+	TaskID     uuid.UUID
+	Version    int64
+	Type       string
+	Data       []byte
+	OccurredAt pgtype.Timestamptz
+}
+
+func (q *Queries) InsertTaskEvent(ctx context.Context, arg InsertTaskEventParams) (int64, error) {
+	row := q.db.QueryRow(ctx, InsertTaskEvent,
+		arg.TaskID,
+		arg.Version,
+		arg.Type,
+		arg.Data,
+		arg.OccurredAt,
+	)
+	var position int64
+	err := row.Scan(&position)
+	return position, err
+}
+
+const InsertTaskSnapshot = `-- name: InsertTaskSnapshot :exec
+INSERT INTO task_snapshots (
+  task_id,
+  version,
+  data,
+  occurred_at
+)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+)
+ON CONFLICT (task_id, version) DO NOTHING
+`
+
+type InsertTaskSnapshotParams struct {
+	TaskID     uuid.UUID
+	Version    int64
+	Data       []byte
+	OccurredAt pgtype.Timestamptz
+}
+
+func (q *Queries) InsertTaskSnapshot(ctx context.Context, arg InsertTaskSnapshotParams) error {
+	_, err := q.db.Exec(ctx, InsertTaskSnapshot,
+		arg.TaskID,
+		arg.Version,
+		arg.Data,
+		arg.OccurredAt,
+	)
+	return err
+}
+
+const LockTasks = `-- name: LockTasks :exec
+LOCK TABLE tasks IN ACCESS EXCLUSIVE MODE
+`
+
+func (q *Queries) LockTasks(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, LockTasks)
+	return err
+}
+
+const SelectSettledTaskEventsAfter = `-- name: SelectSettledTaskEventsAfter :many
+SELECT
+  position,
+  task_id,
+  version,
+  type,
+  data,
+  occurred_at,
+  transaction_id
+FROM
+  task_events
+WHERE
+  (transaction_id, position) > ($1::bigint, $2::bigint) AND
+  transaction_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY
+  transaction_id ASC,
+  position ASC
+LIMIT $3
+`
+
+type SelectSettledTaskEventsAfterParams struct {
+	TransactionID int64
+	Position      int64
+	Size          int32
+}
+
+func (q *Queries) SelectSettledTaskEventsAfter(ctx context.Context, arg SelectSettledTaskEventsAfterParams) ([]TaskEvents, error) {
+	rows, err := q.db.Query(ctx, SelectSettledTaskEventsAfter, arg.TransactionID, arg.Position, arg.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TaskEvents{}
+	for rows.Next() {
+		var i TaskEvents
+		if err := rows.Scan(
+			&i.Position,
+			&i.TaskID,
+			&i.Version,
+			&i.Type,
+			&i.Data,
+			&i.OccurredAt,
+			&i.TransactionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const SelectTaskEvents = `-- name: SelectTaskEvents :many
+SELECT
+  position,
+  task_id,
+  version,
+  type,
+  data,
+  occurred_at,
+  transaction_id
+FROM
+  task_events
+WHERE
+  task_id = $1 AND
+  version > $2 AND
+  ($3::timestamptz IS NULL OR occurred_at <= $3)
+ORDER BY
+  version ASC
+`
+
+type SelectTaskEventsParams struct {
+	TaskID     uuid.UUID
+	Version    int64
+	OccurredAt pgtype.Timestamptz
+}
+
+func (q *Queries) SelectTaskEvents(ctx context.Context, arg SelectTaskEventsParams) ([]TaskEvents, error) {
+	rows, err := q.db.Query(ctx, SelectTaskEvents, arg.TaskID, arg.Version, arg.OccurredAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TaskEvents{}
+	for rows.Next() {
+		var i TaskEvents
+		if err := rows.Scan(
+			&i.Position,
+			&i.TaskID,
+			&i.Version,
+			&i.Type,
+			&i.Data,
+			&i.OccurredAt,
+			&i.TransactionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const SelectTaskEventsAfter = `-- name: SelectTaskEventsAfter :many
+SELECT
+  position,
+  task_id,
+  version,
+  type,
+  data,
+  occurred_at,
+  transaction_id
+FROM
+  task_events
+WHERE
+  position > $1
+ORDER BY
+  position ASC
+LIMIT $2
+`
+
+type SelectTaskEventsAfterParams struct {
+	Position int64
+	Size     int32
+}
+
+func (q *Queries) SelectTaskEventsAfter(ctx context.Context, arg SelectTaskEventsAfterParams) ([]TaskEvents, error) {
+	rows, err := q.db.Query(ctx, SelectTaskEventsAfter, arg.Position, arg.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TaskEvents{}
+	for rows.Next() {
+		var i TaskEvents
+		if err := rows.Scan(
+			&i.Position,
+			&i.TaskID,
+			&i.Version,
+			&i.Type,
+			&i.Data,
+			&i.OccurredAt,
+			&i.TransactionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const SelectTaskSnapshot = `-- name: SelectTaskSnapshot :one
+SELECT
+  task_id,
+  version,
+  data,
+  occurred_at
+FROM
+  task_snapshots
+WHERE
+  task_id = $1 AND
+  ($2::timestamptz IS NULL OR occurred_at <= $2)
+ORDER BY
+  version DESC
+LIMIT 1
+`
+
+type SelectTaskSnapshotParams struct {
+	TaskID     uuid.UUID
+	OccurredAt pgtype.Timestamptz
+}
+
+func (q *Queries) SelectTaskSnapshot(ctx context.Context, arg SelectTaskSnapshotParams) (TaskSnapshots, error) {
+	row := q.db.QueryRow(ctx, SelectTaskSnapshot, arg.TaskID, arg.OccurredAt)
+	var i TaskSnapshots
+	err := row.Scan(
+		&i.TaskID,
+		&i.Version,
+		&i.Data,
+		&i.OccurredAt,
+	)
+	return i, err
+}
+
+const TruncateTasks = `-- name: TruncateTasks :exec
+TRUNCATE tasks
+`
+
+func (q *Queries) TruncateTasks(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, TruncateTasks)
+	return err
+}
+
+const UpsertTask = `-- name: UpsertTask :exec
+INSERT INTO tasks (
+  id,
+  description,
+  priority,
+  start_date,
+  due_date,
+  done,
+  version
+)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7
+)
+ON CONFLICT (id) DO UPDATE SET
+  description = EXCLUDED.description,
+  priority    = EXCLUDED.priority,
+  start_date  = EXCLUDED.start_date,
+  due_date    = EXCLUDED.due_date,
+  done        = EXCLUDED.done,
+  version     = EXCLUDED.version
+`
+
+type UpsertTaskParams struct {
+	ID          uuid.UUID
+	Description string
+	Priority    Priority
+	StartDate   pgtype.Timestamp
+	DueDate     pgtype.Timestamp
+	Done        bool
+	Version     int64
+}
+
+func (q *Queries) UpsertTask(ctx context.Context, arg UpsertTaskParams) error {
+	_, err := q.db.Exec(ctx, UpsertTask,
+		arg.ID,
+		arg.Description,
+		arg.Priority,
+		arg.StartDate,
+		arg.DueDate,
+		arg.Done,
+		arg.Version,
+	)
+	return err
+}
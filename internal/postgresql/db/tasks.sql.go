@@ -12,19 +12,84 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const CountOpenTasksByPriority = `-- name: CountOpenTasksByPriority :many
+SELECT
+  priority,
+  COUNT(*) AS total
+FROM
+  tasks
+WHERE
+  done = FALSE
+GROUP BY
+  priority
+`
+
+type CountOpenTasksByPriorityRow struct {
+	Priority Priority
+	Total    int64
+}
+
+func (q *Queries) CountOpenTasksByPriority(ctx context.Context) ([]CountOpenTasksByPriorityRow, error) {
+	rows, err := q.db.Query(ctx, CountOpenTasksByPriority)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountOpenTasksByPriorityRow{}
+	for rows.Next() {
+		var i CountOpenTasksByPriorityRow
+		if err := rows.Scan(&i.Priority, &i.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const CountSearchTasks = `-- name: CountSearchTasks :one
+SELECT
+  COUNT(*)
+FROM
+  tasks
+  CROSS JOIN (
+    SELECT
+      NULLIF(REPLACE(plainto_tsquery('english', COALESCE($1::text, ''))::text, '&', '|'), '')::tsquery AS query
+  ) AS search
+WHERE
+  description_tsv @@ search.query OR
+  priority = $2 OR
+  done = $3
+`
+
+type CountSearchTasksParams struct {
+	Description pgtype.Text
+	Priority    NullPriority
+	Done        pgtype.Bool
+}
+
+func (q *Queries) CountSearchTasks(ctx context.Context, arg CountSearchTasksParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountSearchTasks, arg.Description, arg.Priority, arg.Done)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const DeleteTask = `-- name: DeleteTask :one
 DELETE FROM
   tasks
 WHERE
   id = $1
-RETURNING id AS res
+RETURNING version
 `
 
-func (q *Queries) DeleteTask(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+func (q *Queries) DeleteTask(ctx context.Context, id uuid.UUID) (int64, error) {
 	row := q.db.QueryRow(ctx, DeleteTask, id)
-	var res uuid.UUID
-	err := row.Scan(&res)
-	return res, err
+	var version int64
+	err := row.Scan(&version)
+	return version, err
 }
 
 const InsertTask = `-- name: InsertTask :one
@@ -40,7 +105,7 @@ VALUES (
   $3,
   $4
 )
-RETURNING id
+RETURNING id, version
 `
 
 type InsertTaskParams struct {
@@ -50,19 +115,24 @@ type InsertTaskParams struct {
 	DueDate     pgtype.Timestamp
 }
 
-func (q *Queries) InsertTask(ctx context.Context, arg InsertTaskParams) (uuid.UUID, error) {
+type InsertTaskRow struct {
+	ID      uuid.UUID
+	Version int64
+}
+
+func (q *Queries) InsertTask(ctx context.Context, arg InsertTaskParams) (InsertTaskRow, error) {
 	row := q.db.QueryRow(ctx, InsertTask,
 		arg.Description,
 		arg.Priority,
 		arg.StartDate,
 		arg.DueDate,
 	)
-	var id uuid.UUID
-	err := row.Scan(&id)
-	return id, err
+	var i InsertTaskRow
+	err := row.Scan(&i.ID, &i.Version)
+	return i, err
 }
 
-const SelectTask = `-- name: SelectTask :one
+const SearchTasks = `-- name: SearchTasks :many
 SELECT
   id,
   description,
@@ -70,6 +140,84 @@ SELECT
   start_date,
   due_date,
   done
+FROM
+  tasks
+  CROSS JOIN (
+    SELECT
+      NULLIF(REPLACE(plainto_tsquery('english', COALESCE($1::text, ''))::text, '&', '|'), '')::tsquery AS query
+  ) AS search
+WHERE
+  description_tsv @@ search.query OR
+  priority = $2 OR
+  done = $3
+ORDER BY
+  COALESCE(ts_rank(description_tsv, search.query), 0) +
+    COALESCE((priority = $2)::int, 0) +
+    COALESCE((done = $3)::int, 0) DESC,
+  id ASC
+LIMIT $5::bigint
+OFFSET $4::bigint
+`
+
+type SearchTasksParams struct {
+	Description pgtype.Text
+	Priority    NullPriority
+	Done        pgtype.Bool
+	From        int64
+	Size        int64
+}
+
+type SearchTasksRow struct {
+	ID          uuid.UUID
+	Description string
+	Priority    Priority
+	StartDate   pgtype.Timestamp
+	DueDate     pgtype.Timestamp
+	Done        bool
+}
+
+func (q *Queries) SearchTasks(ctx context.Context, arg SearchTasksParams) ([]SearchTasksRow, error) {
+	rows, err := q.db.Query(ctx, SearchTasks,
+		arg.Description,
+		arg.Priority,
+		arg.Done,
+		arg.From,
+		arg.Size,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchTasksRow{}
+	for rows.Next() {
+		var i SearchTasksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.Priority,
+			&i.StartDate,
+			&i.DueDate,
+			&i.Done,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const SelectTask = `-- name: SelectTask :one
+SELECT
+  id,
+  description,
+  priority,
+  start_date,
+  due_date,
+  done,
+  version
 FROM
   tasks
 WHERE
@@ -77,9 +225,19 @@ WHERE
 LIMIT 1
 `
 
-func (q *Queries) SelectTask(ctx context.Context, id uuid.UUID) (Tasks, error) {
+type SelectTaskRow struct {
+	ID          uuid.UUID
+	Description string
+	Priority    Priority
+	StartDate   pgtype.Timestamp
+	DueDate     pgtype.Timestamp
+	Done        bool
+	Version     int64
+}
+
+func (q *Queries) SelectTask(ctx context.Context, id uuid.UUID) (SelectTaskRow, error) {
 	row := q.db.QueryRow(ctx, SelectTask, id)
-	var i Tasks
+	var i SelectTaskRow
 	err := row.Scan(
 		&i.ID,
 		&i.Description,
@@ -87,6 +245,7 @@ func (q *Queries) SelectTask(ctx context.Context, id uuid.UUID) (Tasks, error) {
 		&i.StartDate,
 		&i.DueDate,
 		&i.Done,
+		&i.Version,
 	)
 	return i, err
 }
@@ -97,9 +256,10 @@ UPDATE tasks SET
   priority    = $2,
   start_date  = $3,
   due_date    = $4,
-  done        = $5
+  done        = $5,
+  version     = version + 1
 WHERE id = $6
-RETURNING id AS res
+RETURNING version
 `
 
 type UpdateTaskParams struct {
@@ -111,7 +271,7 @@ type UpdateTaskParams struct {
 	ID          uuid.UUID
 }
 
-func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (uuid.UUID, error) {
+func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (int64, error) {
 	row := q.db.QueryRow(ctx, UpdateTask,
 		arg.Description,
 		arg.Priority,
@@ -120,7 +280,7 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (uuid.UU
 		arg.Done,
 		arg.ID,
 	)
-	var res uuid.UUID
-	err := row.Scan(&res)
-	return res, err
+	var version int64
+	err := row.Scan(&version)
+	return version, err
 }
@@ -0,0 +1,544 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/postgresql/db"
+)
+
+const (
+	// TaskEventCreated indicates the task was created.
+	TaskEventCreated = "created"
+
+	// TaskEventUpdated indicates the task was updated, the event includes all the values.
+	TaskEventUpdated = "updated"
+
+	// TaskEventDeleted indicates the task was deleted, it's always the last event of a task.
+	TaskEventDeleted = "deleted"
+)
+
+const (
+	// snapshotInterval indicates how many events are appended before taking a snapshot of the task.
+	snapshotInterval = 50
+
+	// appendAttempts is the number of times an event is appended when a concurrent change used the same version.
+	appendAttempts = 3
+
+	replayBatchSize = 500
+)
+
+// TaskEvent represents a change stored in the event store.
+type TaskEvent struct {
+	// Position indicates the order the event was stored among the events of all tasks, events of concurrent
+	// transactions may be committed in a different order.
+	Position int64
+
+	// TransactionID identifies the transaction that appended the event.
+	TransactionID int64
+
+	Type string
+
+	// Task is the state after the event, deleted events only include the ID and version.
+	Task internal.Task
+
+	OccurredAt time.Time
+}
+
+// ReplayPosition identifies the last event replayed, the zero value replays all the events.
+type ReplayPosition struct {
+	TransactionID int64
+	Position      int64
+}
+
+// EventSourcedTask represents the repository used for interacting with Task records stored as a sequence of events,
+// the state is rebuilt from the latest snapshot and the following events. The tasks table is kept up to date as a
+// projection, in the same transaction, so searching using PostgreSQL works the same way.
+type EventSourcedTask struct {
+	pool *pgxpool.Pool
+	q    *db.Queries
+}
+
+// NewEventSourcedTask instantiates the EventSourcedTask repository.
+func NewEventSourcedTask(pool *pgxpool.Pool) *EventSourcedTask {
+	return &EventSourcedTask{
+		pool: pool,
+		q:    db.New(pool),
+	}
+}
+
+// taskEventData is the representation of the task stored in events and snapshots.
+type taskEventData struct {
+	Description string      `json:"description,omitempty"`
+	Priority    db.Priority `json:"priority,omitempty"`
+	StartDate   *time.Time  `json:"start_date,omitempty"`
+	DueDate     *time.Time  `json:"due_date,omitempty"`
+	Done        bool        `json:"done,omitempty"`
+}
+
+// Create appends the event creating a new task.
+func (t *EventSourcedTask) Create(ctx context.Context, params internal.CreateParams) (internal.Task, error) {
+	defer newOTELSpan(ctx, "EventSourcedTask.Create").End()
+
+	task := internal.Task{
+		ID:          uuid.NewString(),
+		Description: params.Description,
+		Priority:    params.Priority,
+		Dates:       params.Dates,
+		Version:     1,
+	}
+
+	if err := t.append(ctx, TaskEventCreated, task); err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "append")
+	}
+
+	return task, nil
+}
+
+// Delete appends the event deleting the existing task matching the id, the version of the deletion is the version
+// of the event.
+func (t *EventSourcedTask) Delete(ctx context.Context, id string) (int64, error) {
+	defer newOTELSpan(ctx, "EventSourcedTask.Delete").End()
+
+	res, err := t.change(ctx, id, TaskEventDeleted, func(current internal.Task) internal.Task {
+		return internal.Task{
+			ID:      current.ID,
+			Version: current.Version + 1,
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Version, nil
+}
+
+// Find returns the current state of the requested task.
+func (t *EventSourcedTask) Find(ctx context.Context, id string) (internal.Task, error) {
+	defer newOTELSpan(ctx, "EventSourcedTask.Find").End()
+
+	return t.find(ctx, id, pgtype.Timestamptz{})
+}
+
+// FindAt returns the state of the requested task at the specified time, it fails with internal.ErrorCodeNotFound
+// if the task didn't exist at that time.
+func (t *EventSourcedTask) FindAt(ctx context.Context, id string, at time.Time) (internal.Task, error) {
+	defer newOTELSpan(ctx, "EventSourcedTask.FindAt").End()
+
+	return t.find(ctx, id, pgtype.Timestamptz{Time: at, Valid: true})
+}
+
+// Update appends the event updating the existing task with new values and returns the updated task.
+func (t *EventSourcedTask) Update(ctx context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) (internal.Task, error) {
+	defer newOTELSpan(ctx, "EventSourcedTask.Update").End()
+
+	return t.change(ctx, id, TaskEventUpdated, func(current internal.Task) internal.Task {
+		return internal.Task{
+			ID:          current.ID,
+			Description: description,
+			Priority:    priority,
+			Dates:       dates,
+			IsDone:      isDone,
+			Version:     current.Version + 1,
+		}
+	})
+}
+
+// History returns all the events of the requested task, oldest first.
+func (t *EventSourcedTask) History(ctx context.Context, id string) ([]TaskEvent, error) {
+	defer newOTELSpan(ctx, "EventSourcedTask.History").End()
+
+	val, err := uuid.Parse(id)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
+	}
+
+	rows, err := t.q.SelectTaskEvents(ctx, db.SelectTaskEventsParams{TaskID: val})
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "select task events")
+	}
+
+	if len(rows) == 0 {
+		return nil, internal.NewErrorf(internal.ErrorCodeNotFound, "task not found")
+	}
+
+	return newTaskEvents(rows)
+}
+
+// Replay calls fn for every event of all tasks stored after the position, ordered by the transaction that appended
+// them, and returns the position of the last one. It is used for building new read models, the returned position
+// can be used for continuing later. Positions are assigned before events are committed, so only events appended by
+// transactions older than any transaction in progress are replayed; events committed afterwards are never skipped,
+// they are replayed by a later call.
+func (t *EventSourcedTask) Replay(ctx context.Context, after ReplayPosition, fn func(ctx context.Context, evt TaskEvent) error) (ReplayPosition, error) {
+	defer newOTELSpan(ctx, "EventSourcedTask.Replay").End()
+
+	return replay(ctx, after, func(after ReplayPosition) ([]db.TaskEvents, error) {
+		return t.q.SelectSettledTaskEventsAfter(ctx, db.SelectSettledTaskEventsAfterParams{
+			TransactionID: after.TransactionID,
+			Position:      after.Position,
+			Size:          replayBatchSize,
+		})
+	}, fn)
+}
+
+// RebuildProjection replaces the rows in the tasks table with the state rebuilt from all the events. Tasks without
+// events, like the ones created before switching to event sourcing, would be removed, so it fails with
+// internal.ErrorCodeInvalidArgument when any exists unless force is set.
+func (t *EventSourcedTask) RebuildProjection(ctx context.Context, force bool) error {
+	defer newOTELSpan(ctx, "EventSourcedTask.RebuildProjection").End()
+
+	err := pgx.BeginFunc(ctx, t.pool, func(tx pgx.Tx) error {
+		q := t.q.WithTx(tx)
+
+		// Changes in progress complete before the lock is granted and the ones following it wait until the
+		// projection is rebuilt, so the events read below include all the committed changes.
+		if err := q.LockTasks(ctx); err != nil {
+			return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "lock tasks")
+		}
+
+		if !force {
+			count, err := q.CountTasksWithoutEvents(ctx)
+			if err != nil {
+				return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "count tasks without events")
+			}
+
+			if count > 0 {
+				return internal.NewErrorf(internal.ErrorCodeInvalidArgument, "%d tasks have no events and would be removed", count)
+			}
+		}
+
+		if err := q.TruncateTasks(ctx); err != nil {
+			return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "truncate tasks")
+		}
+
+		_, err := replay(ctx, ReplayPosition{}, func(after ReplayPosition) ([]db.TaskEvents, error) {
+			return q.SelectTaskEventsAfter(ctx, db.SelectTaskEventsAfterParams{
+				Position: after.Position,
+				Size:     replayBatchSize,
+			})
+		}, func(ctx context.Context, evt TaskEvent) error {
+			return project(ctx, q, evt.Type, evt.Task)
+		})
+
+		return err
+	})
+	if err != nil {
+		return internal.WrapErrorf(err, errorCode(err), "pgx.BeginFunc")
+	}
+
+	return nil
+}
+
+func (t *EventSourcedTask) find(ctx context.Context, id string, at pgtype.Timestamptz) (internal.Task, error) {
+	val, err := uuid.Parse(id)
+	if err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
+	}
+
+	task, err := t.load(ctx, val, at)
+	if err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, errorCode(err), "load")
+	}
+
+	return task, nil
+}
+
+// change appends the event returned by fn, which receives the current state, and returns the appended state. When
+// another change is appended concurrently the current state is loaded again.
+func (t *EventSourcedTask) change(ctx context.Context, id, eventType string, fn func(current internal.Task) internal.Task) (internal.Task, error) {
+	val, err := uuid.Parse(id)
+	if err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
+	}
+
+	for attempt := 1; ; attempt++ {
+		current, err := t.load(ctx, val, pgtype.Timestamptz{})
+		if err != nil {
+			return internal.Task{}, internal.WrapErrorf(err, errorCode(err), "load")
+		}
+
+		next := fn(current)
+
+		err = t.append(ctx, eventType, next)
+		if err == nil {
+			return next, nil
+		}
+
+		if !isUniqueViolation(err) || attempt >= appendAttempts {
+			return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "append")
+		}
+	}
+}
+
+// load rebuilds the task from the latest snapshot and the following events, only events that occurred up to at are
+// considered when it is valid.
+func (t *EventSourcedTask) load(ctx context.Context, id uuid.UUID, at pgtype.Timestamptz) (internal.Task, error) {
+	var (
+		res     internal.Task
+		deleted bool
+	)
+
+	snapshot, err := t.q.SelectTaskSnapshot(ctx, db.SelectTaskSnapshotParams{
+		TaskID:     id,
+		OccurredAt: at,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "select task snapshot")
+	}
+
+	if err == nil {
+		if res, err = newTaskFromData(snapshot.Data); err != nil {
+			return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newTaskFromData")
+		}
+
+		res.Version = snapshot.Version
+	}
+
+	rows, err := t.q.SelectTaskEvents(ctx, db.SelectTaskEventsParams{
+		TaskID:     id,
+		Version:    res.Version,
+		OccurredAt: at,
+	})
+	if err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "select task events")
+	}
+
+	for _, row := range rows {
+		switch row.Type {
+		case TaskEventCreated, TaskEventUpdated:
+			if res, err = newTaskFromData(row.Data); err != nil {
+				return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newTaskFromData")
+			}
+		case TaskEventDeleted:
+			deleted = true
+		}
+
+		res.Version = row.Version
+	}
+
+	if res.Version == 0 || deleted {
+		return internal.Task{}, internal.NewErrorf(internal.ErrorCodeNotFound, "task not found")
+	}
+
+	res.ID = id.String()
+
+	return res, nil
+}
+
+// append stores the event and updates the projection in the same transaction, appending fails with a unique
+// violation when an event with the same version exists already.
+func (t *EventSourcedTask) append(ctx context.Context, eventType string, task internal.Task) error {
+	id, err := uuid.Parse(task.ID)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
+	}
+
+	data, err := newTaskEventData(eventType, task)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newTaskEventData")
+	}
+
+	occurredAt := pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true}
+
+	return pgx.BeginFunc(ctx, t.pool, func(tx pgx.Tx) error {
+		q := t.q.WithTx(tx)
+
+		_, err := q.InsertTaskEvent(ctx, db.InsertTaskEventParams{
+			TaskID:     id,
+			Version:    task.Version,
+			Type:       eventType,
+			Data:       data,
+			OccurredAt: occurredAt,
+		})
+		if err != nil {
+			return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "insert task event")
+		}
+
+		if eventType != TaskEventDeleted && task.Version%snapshotInterval == 0 {
+			err := q.InsertTaskSnapshot(ctx, db.InsertTaskSnapshotParams{
+				TaskID:     id,
+				Version:    task.Version,
+				Data:       data,
+				OccurredAt: occurredAt,
+			})
+			if err != nil {
+				return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "insert task snapshot")
+			}
+		}
+
+		return project(ctx, q, eventType, task)
+	})
+}
+
+// project updates the tasks table with the state after the event.
+func project(ctx context.Context, q *db.Queries, eventType string, task internal.Task) error {
+	id, err := uuid.Parse(task.ID)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
+	}
+
+	if eventType == TaskEventDeleted {
+		if err := q.DeleteTaskProjection(ctx, id); err != nil {
+			return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "delete task projection")
+		}
+
+		return nil
+	}
+
+	err = q.UpsertTask(ctx, db.UpsertTaskParams{
+		ID:          id,
+		Description: task.Description,
+		Priority:    newPriority(task.Priority),
+		StartDate:   newTimeStamp(task.Dates.Start),
+		DueDate:     newTimeStamp(task.Dates.Due),
+		Done:        task.IsDone,
+		Version:     task.Version,
+	})
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "upsert task")
+	}
+
+	return nil
+}
+
+// replay calls fn for every event returned by selectAfter, which returns the batch of events following a position,
+// until a batch is not full.
+func replay(ctx context.Context, position ReplayPosition, selectAfter func(after ReplayPosition) ([]db.TaskEvents, error), fn func(ctx context.Context, evt TaskEvent) error) (ReplayPosition, error) {
+	for {
+		rows, err := selectAfter(position)
+		if err != nil {
+			return position, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "select task events after")
+		}
+
+		evts, err := newTaskEvents(rows)
+		if err != nil {
+			return position, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newTaskEvents")
+		}
+
+		for _, evt := range evts {
+			if err := fn(ctx, evt); err != nil {
+				return position, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "fn")
+			}
+
+			position = ReplayPosition{TransactionID: evt.TransactionID, Position: evt.Position}
+		}
+
+		if len(rows) < replayBatchSize {
+			return position, nil
+		}
+	}
+}
+
+func newTaskEvents(rows []db.TaskEvents) ([]TaskEvent, error) {
+	res := make([]TaskEvent, len(rows))
+
+	for i, row := range rows {
+		var (
+			task internal.Task
+			err  error
+		)
+
+		if row.Type != TaskEventDeleted {
+			if task, err = newTaskFromData(row.Data); err != nil {
+				return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newTaskFromData")
+			}
+		}
+
+		task.ID = row.TaskID.String()
+		task.Version = row.Version
+
+		res[i] = TaskEvent{
+			Position:      row.Position,
+			TransactionID: row.TransactionID,
+			Type:          row.Type,
+			Task:          task,
+			OccurredAt:    row.OccurredAt.Time,
+		}
+	}
+
+	return res, nil
+}
+
+func newTaskEventData(eventType string, task internal.Task) ([]byte, error) {
+	var data taskEventData
+
+	if eventType != TaskEventDeleted {
+		data = taskEventData{
+			Description: task.Description,
+			Priority:    newPriority(task.Priority),
+			StartDate:   newTimePointer(task.Dates.Start),
+			DueDate:     newTimePointer(task.Dates.Due),
+			Done:        task.IsDone,
+		}
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Marshal")
+	}
+
+	return b, nil
+}
+
+func newTaskFromData(b []byte) (internal.Task, error) {
+	var data taskEventData
+
+	if err := json.Unmarshal(b, &data); err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Unmarshal")
+	}
+
+	priority, err := convertPriority(data.Priority)
+	if err != nil {
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "convert priority")
+	}
+
+	res := internal.Task{
+		Description: data.Description,
+		Priority:    priority,
+		IsDone:      data.Done,
+	}
+
+	if data.StartDate != nil {
+		res.Dates.Start = *data.StartDate
+	}
+
+	if data.DueDate != nil {
+		res.Dates.Due = *data.DueDate
+	}
+
+	return res, nil
+}
+
+func newTimePointer(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// errorCode returns the code of err so not found errors are kept when wrapping it.
+func errorCode(err error) internal.ErrorCode {
+	var ierr *internal.Error
+	if errors.As(err, &ierr) {
+		return ierr.Code()
+	}
+
+	return internal.ErrorCodeUnkown
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,157 @@
+package postgresql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/postgresql"
+	"github.com/sanLimbu/todo-api/internal/postgresql/db"
+	"github.com/sanLimbu/todo-api/internal/service"
+	"github.com/sanLimbu/todo-api/internal/service/servicetesting"
+)
+
+func TestEventSourcedTask(t *testing.T) {
+	pool := newPool(t)
+
+	// Events are appended in their own transactions, the tasks created by the contract are committed.
+	servicetesting.TaskRepository(t, func(_ *testing.T) service.TaskRepository {
+		return postgresql.NewEventSourcedTask(pool)
+	})
+}
+
+func TestEventSourcedTask_Replay(t *testing.T) {
+	pool := newPool(t)
+	repo := postgresql.NewEventSourcedTask(pool)
+
+	created, err := repo.Create(context.Background(), internal.CreateParams{Description: "replayed"})
+	if err != nil {
+		t.Fatalf("Create failed: %s", err)
+	}
+
+	var found bool
+
+	position, err := repo.Replay(context.Background(), postgresql.ReplayPosition{}, func(_ context.Context, evt postgresql.TaskEvent) error {
+		if evt.Task.ID == created.ID && evt.Type == postgresql.TaskEventCreated {
+			found = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %s", err)
+	}
+
+	if !found {
+		t.Fatalf("expected the created event to be replayed")
+	}
+
+	_, err = repo.Replay(context.Background(), position, func(_ context.Context, evt postgresql.TaskEvent) error {
+		if evt.Task.ID == created.ID && evt.Type == postgresql.TaskEventCreated {
+			t.Fatalf("expected events before the position not to be replayed")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %s", err)
+	}
+}
+
+func TestEventSourcedTask_ReplayConcurrentAppend(t *testing.T) {
+	pool := newPool(t)
+	repo := postgresql.NewEventSourcedTask(pool)
+	ctx := context.Background()
+
+	replayed := func(after postgresql.ReplayPosition) (map[string]bool, postgresql.ReplayPosition) {
+		t.Helper()
+
+		ids := make(map[string]bool)
+
+		position, err := repo.Replay(ctx, after, func(_ context.Context, evt postgresql.TaskEvent) error {
+			ids[evt.Task.ID] = true
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Replay failed: %s", err)
+		}
+
+		return ids, position
+	}
+
+	_, position := replayed(postgresql.ReplayPosition{})
+
+	// The event appended by the transaction in progress gets a position before the one committed afterwards.
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("pool.Begin: %s", err)
+	}
+
+	t.Cleanup(func() { _ = tx.Rollback(ctx) })
+
+	pending := uuid.New()
+
+	if _, err := db.New(tx).InsertTaskEvent(ctx, db.InsertTaskEventParams{
+		TaskID:     pending,
+		Version:    1,
+		Type:       postgresql.TaskEventCreated,
+		Data:       []byte(`{"description":"pending"}`),
+		OccurredAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}); err != nil {
+		t.Fatalf("InsertTaskEvent failed: %s", err)
+	}
+
+	committed, err := repo.Create(ctx, internal.CreateParams{Description: "committed"})
+	if err != nil {
+		t.Fatalf("Create failed: %s", err)
+	}
+
+	// Events committed after the transaction in progress began wait until it completes.
+	ids, position := replayed(position)
+	if ids[pending.String()] || ids[committed.ID] {
+		t.Fatalf("expected events after the transaction in progress not to be replayed")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %s", err)
+	}
+
+	t.Cleanup(func() {
+		_, _ = pool.Exec(ctx, "DELETE FROM task_events WHERE task_id = $1", pending)
+	})
+
+	// Continuing from the returned position replays both events.
+	ids, _ = replayed(position)
+	if !ids[pending.String()] || !ids[committed.ID] {
+		t.Fatalf("expected the events to be replayed once the transaction completes, got %v", ids)
+	}
+}
+
+func TestEventSourcedTask_RebuildProjection(t *testing.T) {
+	pool := newPool(t)
+
+	// Tasks created without event sourcing have no events.
+	task, err := postgresql.NewTask(pool).Create(context.Background(), internal.CreateParams{Description: "without events"})
+	if err != nil {
+		t.Fatalf("Create failed: %s", err)
+	}
+
+	t.Cleanup(func() { _, _ = postgresql.NewTask(pool).Delete(context.Background(), task.ID) })
+
+	err = postgresql.NewEventSourcedTask(pool).RebuildProjection(context.Background(), false)
+
+	var ierr *internal.Error
+	if !errors.As(err, &ierr) || ierr.Code() != internal.ErrorCodeInvalidArgument {
+		t.Fatalf("expected invalid argument error, got %v", err)
+	}
+
+	if _, err := postgresql.NewTask(pool).Find(context.Background(), task.ID); err != nil {
+		t.Fatalf("expected the task to be kept: %s", err)
+	}
+}
@@ -0,0 +1,58 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/postgresql/db"
+)
+
+// observeTimeout bounds the queries run when metrics are collected.
+const observeTimeout = time.Second
+
+// ObserveOpenTasks reports the number of tasks not done yet by priority every time metrics are collected, nothing is
+// reported when querying fails.
+func ObserveOpenTasks(pool *pgxpool.Pool) error {
+	q := db.New(pool)
+
+	_, err := otel.Meter(otelName).Int64ObservableGauge("tasks.open",
+		metric.WithDescription("Number of tasks not done yet by priority"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			ctx, cancel := context.WithTimeout(ctx, observeTimeout)
+			defer cancel()
+
+			rows, err := q.CountOpenTasksByPriority(ctx)
+			if err != nil {
+				return nil
+			}
+
+			// Priorities without open tasks are reported as well so they don't disappear from the series.
+			totals := map[db.Priority]int64{
+				db.PriorityNone:   0,
+				db.PriorityLow:    0,
+				db.PriorityMedium: 0,
+				db.PriorityHigh:   0,
+			}
+
+			for _, row := range rows {
+				totals[row.Priority] = row.Total
+			}
+
+			for priority, total := range totals {
+				o.Observe(total, metric.WithAttributes(attribute.String("priority", string(priority))))
+			}
+
+			return nil
+		}))
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "Int64ObservableGauge")
+	}
+
+	return nil
+}
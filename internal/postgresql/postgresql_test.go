@@ -0,0 +1,45 @@
+package postgresql_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newPool connects to the database defined in POSTGRESQL_TEST_URL, the tests are skipped when it is not set. The
+// database must be migrated using the files in db/migrations.
+func newPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("POSTGRESQL_TEST_URL")
+	if url == "" {
+		t.Skip("POSTGRESQL_TEST_URL is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("pgxpool.New: %s", err)
+	}
+
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+// newTx begins a transaction rolled back when the test finishes, so records created by the test are never
+// committed.
+func newTx(t *testing.T, pool *pgxpool.Pool) pgx.Tx {
+	t.Helper()
+
+	tx, err := pool.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("pool.Begin: %s", err)
+	}
+
+	t.Cleanup(func() { _ = tx.Rollback(context.Background()) })
+
+	return tx
+}
@@ -0,0 +1,147 @@
+-- name: InsertTaskEvent :one
+INSERT INTO task_events (
+  task_id,
+  version,
+  type,
+  data,
+  occurred_at
+)
+VALUES (
+  @task_id,
+  @version,
+  @type,
+  @data,
+  @occurred_at
+)
+RETURNING position;
+
+-- name: SelectTaskEvents :many
+SELECT
+  position,
+  task_id,
+  version,
+  type,
+  data,
+  occurred_at,
+  transaction_id
+FROM
+  task_events
+WHERE
+  task_id = @task_id AND
+  version > @version AND
+  (sqlc.narg('occurred_at')::timestamptz IS NULL OR occurred_at <= sqlc.narg('occurred_at'))
+ORDER BY
+  version ASC;
+
+-- name: SelectTaskEventsAfter :many
+SELECT
+  position,
+  task_id,
+  version,
+  type,
+  data,
+  occurred_at,
+  transaction_id
+FROM
+  task_events
+WHERE
+  position > @position
+ORDER BY
+  position ASC
+LIMIT @size;
+
+-- name: SelectSettledTaskEventsAfter :many
+SELECT
+  position,
+  task_id,
+  version,
+  type,
+  data,
+  occurred_at,
+  transaction_id
+FROM
+  task_events
+WHERE
+  (transaction_id, position) > (@transaction_id::bigint, @position::bigint) AND
+  transaction_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY
+  transaction_id ASC,
+  position ASC
+LIMIT @size;
+
+-- name: CountTasksWithoutEvents :one
+SELECT
+  COUNT(*)
+FROM
+  tasks
+WHERE
+  NOT EXISTS (SELECT 1 FROM task_events WHERE task_events.task_id = tasks.id);
+
+-- name: LockTasks :exec
+LOCK TABLE tasks IN ACCESS EXCLUSIVE MODE;
+
+-- name: SelectTaskSnapshot :one
+SELECT
+  task_id,
+  version,
+  data,
+  occurred_at
+FROM
+  task_snapshots
+WHERE
+  task_id = @task_id AND
+  (sqlc.narg('occurred_at')::timestamptz IS NULL OR occurred_at <= sqlc.narg('occurred_at'))
+ORDER BY
+  version DESC
+LIMIT 1;
+
+-- name: InsertTaskSnapshot :exec
+INSERT INTO task_snapshots (
+  task_id,
+  version,
+  data,
+  occurred_at
+)
+VALUES (
+  @task_id,
+  @version,
+  @data,
+  @occurred_at
+)
+ON CONFLICT (task_id, version) DO NOTHING;
+
+-- name: UpsertTask :exec
+INSERT INTO tasks (
+  id,
+  description,
+  priority,
+  start_date,
+  due_date,
+  done,
+  version
+)
+VALUES (
+  @id,
+  @description,
+  @priority,
+  @start_date,
+  @due_date,
+  @done,
+  @version
+)
+ON CONFLICT (id) DO UPDATE SET
+  description = EXCLUDED.description,
+  priority    = EXCLUDED.priority,
+  start_date  = EXCLUDED.start_date,
+  due_date    = EXCLUDED.due_date,
+  done        = EXCLUDED.done,
+  version     = EXCLUDED.version;
+
+-- name: DeleteTaskProjection :exec
+DELETE FROM
+  tasks
+WHERE
+  id = @id;
+
+-- name: TruncateTasks :exec
+TRUNCATE tasks;
@@ -5,7 +5,8 @@ SELECT
   priority,
   start_date,
   due_date,
-  done
+  done,
+  version
 FROM
   tasks
 WHERE
@@ -25,7 +26,7 @@ VALUES (
   @start_date,
   @due_date
 )
-RETURNING id;
+RETURNING id, version;
 
 -- name: UpdateTask :one
 UPDATE tasks SET
@@ -33,13 +34,65 @@ UPDATE tasks SET
   priority    = @priority,
   start_date  = @start_date,
   due_date    = @due_date,
-  done        = @done
+  done        = @done,
+  version     = version + 1
 WHERE id = @id
-RETURNING id AS res;
+RETURNING version;
 
 -- name: DeleteTask :one
 DELETE FROM
   tasks
 WHERE
   id = @id
-RETURNING id AS res;
+RETURNING version;
+
+-- name: SearchTasks :many
+SELECT
+  id,
+  description,
+  priority,
+  start_date,
+  due_date,
+  done
+FROM
+  tasks
+  CROSS JOIN (
+    SELECT
+      NULLIF(REPLACE(plainto_tsquery('english', COALESCE(sqlc.narg('description')::text, ''))::text, '&', '|'), '')::tsquery AS query
+  ) AS search
+WHERE
+  description_tsv @@ search.query OR
+  priority = sqlc.narg('priority') OR
+  done = sqlc.narg('done')
+ORDER BY
+  COALESCE(ts_rank(description_tsv, search.query), 0) +
+    COALESCE((priority = sqlc.narg('priority'))::int, 0) +
+    COALESCE((done = sqlc.narg('done'))::int, 0) DESC,
+  id ASC
+LIMIT sqlc.arg('size')::bigint
+OFFSET sqlc.arg('from')::bigint;
+
+-- name: CountSearchTasks :one
+SELECT
+  COUNT(*)
+FROM
+  tasks
+  CROSS JOIN (
+    SELECT
+      NULLIF(REPLACE(plainto_tsquery('english', COALESCE(sqlc.narg('description')::text, ''))::text, '&', '|'), '')::tsquery AS query
+  ) AS search
+WHERE
+  description_tsv @@ search.query OR
+  priority = sqlc.narg('priority') OR
+  done = sqlc.narg('done');
+
+-- name: CountOpenTasksByPriority :many
+SELECT
+  priority,
+  COUNT(*) AS total
+FROM
+  tasks
+WHERE
+  done = FALSE
+GROUP BY
+  priority;
@@ -0,0 +1,186 @@
+package postgresql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/postgresql/db"
+)
+
+// SearchableTask represents the repository used for searching Task records using PostgreSQL full text search.
+type SearchableTask struct {
+	q *db.Queries
+}
+
+// NewSearchableTask instantiates the SearchableTask repository.
+func NewSearchableTask(d db.DBTX) *SearchableTask {
+	return &SearchableTask{
+		q: db.New(d),
+	}
+}
+
+// Index is a no-op, the search vector is a generated column kept up to date by PostgreSQL.
+func (t *SearchableTask) Index(_ context.Context, _ internal.Task) error {
+	return nil
+}
+
+// Delete is a no-op, deleting the task record already removes it from the search results.
+func (t *SearchableTask) Delete(_ context.Context, _ string, _ int64) error {
+	return nil
+}
+
+// Search returns tasks matching any of the received values, sorted by relevance.
+func (t *SearchableTask) Search(ctx context.Context, args internal.SearchParams) (internal.SearchResults, error) {
+	defer newOTELSpan(ctx, "SearchableTask.Search").End()
+
+	if args.IsZero() {
+		return internal.SearchResults{}, nil
+	}
+
+	queryHash, err := newQueryHash(args)
+	if err != nil {
+		return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newQueryHash")
+	}
+
+	from := args.From
+
+	if args.Cursor != "" {
+		from, err = decodeSearchCursor(args.Cursor, queryHash)
+		if err != nil {
+			return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "decodeSearchCursor")
+		}
+	}
+
+	params := db.SearchTasksParams{
+		From: from,
+		Size: args.Size,
+	}
+
+	if args.Description != nil {
+		params.Description = pgtype.Text{String: *args.Description, Valid: true}
+	}
+
+	if args.Priority != nil {
+		params.Priority = db.NullPriority{Priority: newPriority(*args.Priority), Valid: true}
+	}
+
+	if args.IsDone != nil {
+		params.Done = pgtype.Bool{Bool: *args.IsDone, Valid: true}
+	}
+
+	rows, err := t.q.SearchTasks(ctx, params)
+	if err != nil {
+		return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "search tasks")
+	}
+
+	// The total is counted separately because pages past the last match have no rows to include it.
+	total, err := t.q.CountSearchTasks(ctx, db.CountSearchTasksParams{
+		Description: params.Description,
+		Priority:    params.Priority,
+		Done:        params.Done,
+	})
+	if err != nil {
+		return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "count search tasks")
+	}
+
+	res := make([]internal.Task, len(rows))
+
+	for i, row := range rows {
+		priority, err := convertPriority(row.Priority)
+		if err != nil {
+			return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "convert priority")
+		}
+
+		res[i] = internal.Task{
+			ID:          row.ID.String(),
+			Description: row.Description,
+			Priority:    priority,
+			Dates: internal.Dates{
+				Start: row.StartDate.Time,
+				Due:   row.DueDate.Time,
+			},
+			IsDone: row.Done,
+		}
+	}
+
+	var nextCursor string
+
+	if count := int64(len(rows)); count > 0 && count == args.Size && from+count < total {
+		nextCursor, err = encodeSearchCursor(from+count, queryHash)
+		if err != nil {
+			return internal.SearchResults{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "encodeSearchCursor")
+		}
+	}
+
+	return internal.SearchResults{
+		Task:       res,
+		Total:      total,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// searchCursor represents the opaque value returned to clients for requesting the next page of results.
+type searchCursor struct {
+	From  int64  `json:"from"`
+	Query string `json:"query"` // hash of the search arguments, see newQueryHash.
+}
+
+func encodeSearchCursor(from int64, query string) (string, error) {
+	b, err := json.Marshal(searchCursor{From: from, Query: query})
+	if err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Marshal")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeSearchCursor returns the offset of the cursor, it must have been created for the search arguments hashed as
+// query.
+func decodeSearchCursor(v, query string) (int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "base64.DecodeString")
+	}
+
+	var res searchCursor
+
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "json.Unmarshal")
+	}
+
+	if res.From < 0 {
+		return 0, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "invalid cursor")
+	}
+
+	if res.Query != query {
+		return 0, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "cursor belongs to a different search")
+	}
+
+	return res.From, nil
+}
+
+// newQueryHash returns the hash identifying the values searched, size is not included so pages can have
+// different sizes.
+func newQueryHash(args internal.SearchParams) (string, error) {
+	b, err := json.Marshal(struct {
+		Description *string            `json:"description"`
+		Priority    *internal.Priority `json:"priority"`
+		IsDone      *bool              `json:"is_done"`
+	}{
+		Description: args.Description,
+		Priority:    args.Priority,
+		IsDone:      args.IsDone,
+	})
+	if err != nil {
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Marshal")
+	}
+
+	sum := sha256.Sum256(b)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
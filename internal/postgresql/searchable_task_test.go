@@ -0,0 +1,84 @@
+package postgresql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/postgresql"
+	"github.com/sanLimbu/todo-api/internal/service/servicetesting"
+)
+
+func TestSearchableTask(t *testing.T) {
+	pool := newPool(t)
+
+	servicetesting.TaskSearchRepository(t, func(t *testing.T) servicetesting.SearchFixture {
+		tx := newTx(t, pool)
+
+		// The contract expects only the tasks it stores to be searchable, the deletion is rolled back with the
+		// transaction.
+		if _, err := tx.Exec(context.Background(), "DELETE FROM tasks"); err != nil {
+			t.Fatalf("tx.Exec: %s", err)
+		}
+
+		repo := postgresql.NewTask(tx)
+
+		return servicetesting.SearchFixture{
+			Search: postgresql.NewSearchableTask(tx),
+			Store: func(ctx context.Context, task internal.Task) (internal.Task, error) {
+				created, err := repo.Create(ctx, internal.CreateParams{
+					Description: task.Description,
+					Priority:    task.Priority,
+					Dates:       task.Dates,
+				})
+				if err != nil {
+					return internal.Task{}, err
+				}
+
+				if task.IsDone {
+					if _, err := repo.Update(ctx, created.ID, task.Description, task.Priority, task.Dates, true); err != nil {
+						return internal.Task{}, err
+					}
+
+					created.IsDone = true
+				}
+
+				return created, nil
+			},
+			Remove: func(ctx context.Context, id string) error {
+				_, err := repo.Delete(ctx, id)
+
+				return err
+			},
+		}
+	})
+}
+
+func TestSearchableTask_TotalPastLastPage(t *testing.T) {
+	tx := newTx(t, newPool(t))
+
+	if _, err := tx.Exec(context.Background(), "DELETE FROM tasks"); err != nil {
+		t.Fatalf("tx.Exec: %s", err)
+	}
+
+	for _, description := range []string{"buy milk", "spilled milk"} {
+		if _, err := postgresql.NewTask(tx).Create(context.Background(), internal.CreateParams{Description: description}); err != nil {
+			t.Fatalf("Create failed: %s", err)
+		}
+	}
+
+	description := "milk"
+
+	res, err := postgresql.NewSearchableTask(tx).Search(context.Background(), internal.SearchParams{
+		Description: &description,
+		From:        5,
+		Size:        10,
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %s", err)
+	}
+
+	if len(res.Task) != 0 || res.Total != 2 {
+		t.Fatalf("expected no tasks and total 2, got %d tasks and total %d", len(res.Task), res.Total)
+	}
+}
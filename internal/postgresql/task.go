@@ -2,7 +2,6 @@ package postgresql
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 
 	"github.com/sanLimbu/todo-api/internal"
@@ -10,6 +9,7 @@ import (
 	"github.com/sanLimbu/todo-api/internal/postgresql/db"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 //Task represents the repository used for interacting with Task records
@@ -29,7 +29,7 @@ func (t *Task) Create(ctx context.Context, params internal.CreateParams) (intern
 
 	defer newOTELSpan(ctx, "Task.Create").End()
 
-	id, err := t.q.InsertTask(ctx, db.InsertTaskParams{
+	res, err := t.q.InsertTask(ctx, db.InsertTaskParams{
 		Description: params.Description,
 		Priority:    newPriority(params.Priority),
 		StartDate:   newTimeStamp(params.Dates.Start),
@@ -41,31 +41,33 @@ func (t *Task) Create(ctx context.Context, params internal.CreateParams) (intern
 	}
 
 	return internal.Task{
-		ID:          id.String(),
+		ID:          res.ID.String(),
 		Description: params.Description,
 		Priority:    params.Priority,
 		Dates:       params.Dates,
+		Version:     res.Version,
 	}, nil
 
 }
 
-//Delete deletes the existing record matching the id
-func (t *Task) Delete(ctx context.Context, id string) error {
+//Delete deletes the existing record matching the id, the version of the deletion follows the last version of the
+//record.
+func (t *Task) Delete(ctx context.Context, id string) (int64, error) {
 
 	defer newOTELSpan(ctx, "Task.Delete").End()
 
 	val, err := uuid.Parse(id)
 	if err != nil {
-		return internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
 	}
-	_, err = t.q.DeleteTask(ctx, val)
+	version, err := t.q.DeleteTask(ctx, val)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return internal.WrapErrorf(err, internal.ErrorCodeNotFound, "task not found")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, internal.WrapErrorf(err, internal.ErrorCodeNotFound, "task not found")
 		}
-		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "delete task")
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "delete task")
 	}
-	return nil
+	return version + 1, nil
 
 }
 
@@ -81,7 +83,7 @@ func (t *Task) Find(ctx context.Context, id string) (internal.Task, error) {
 
 	res, err := t.q.SelectTask(ctx, val)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeNotFound, "task not found")
 		}
 		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "select task")
@@ -100,36 +102,45 @@ func (t *Task) Find(ctx context.Context, id string) (internal.Task, error) {
 			Start: res.StartDate.Time,
 			Due:   res.DueDate.Time,
 		},
-		IsDone: res.Done,
+		IsDone:  res.Done,
+		Version: res.Version,
 	}, nil
 
 }
 
-// Update updates the existing record with new values.
-func (t *Task) Update(ctx context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) error {
+// Update updates the existing record with new values and returns it.
+func (t *Task) Update(ctx context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) (internal.Task, error) {
 
-	defer newOTELSpan(ctx, "Task.Find").End()
+	defer newOTELSpan(ctx, "Task.Update").End()
 
 	// XXX: We will revisit the number of received arguments in future episodes.
 	val, err := uuid.Parse(id)
 	if err != nil {
-		return internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid uuid")
 	}
 
-	if _, err := t.q.UpdateTask(ctx, db.UpdateTaskParams{
+	version, err := t.q.UpdateTask(ctx, db.UpdateTaskParams{
 		ID:          val,
 		Description: description,
 		Priority:    newPriority(priority),
 		StartDate:   newTimeStamp(dates.Start),
 		DueDate:     newTimeStamp(dates.Due),
 		Done:        isDone,
-	}); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return internal.WrapErrorf(err, internal.ErrorCodeNotFound, "task not found")
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeNotFound, "task not found")
 		}
 
-		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "update task")
+		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "update task")
 	}
 
-	return nil
+	return internal.Task{
+		ID:          id,
+		Description: description,
+		Priority:    priority,
+		Dates:       dates,
+		IsDone:      isDone,
+		Version:     version,
+	}, nil
 }
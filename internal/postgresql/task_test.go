@@ -0,0 +1,17 @@
+package postgresql_test
+
+import (
+	"testing"
+
+	"github.com/sanLimbu/todo-api/internal/postgresql"
+	"github.com/sanLimbu/todo-api/internal/service"
+	"github.com/sanLimbu/todo-api/internal/service/servicetesting"
+)
+
+func TestTask(t *testing.T) {
+	pool := newPool(t)
+
+	servicetesting.TaskRepository(t, func(t *testing.T) service.TaskRepository {
+		return postgresql.NewTask(newTx(t, pool))
+	})
+}
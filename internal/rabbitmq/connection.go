@@ -0,0 +1,318 @@
+package rabbitmq
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+const (
+	// confirmTimeout is the maximum time to wait for the broker to confirm a published message, unless the context
+	// is done earlier.
+	confirmTimeout = 5 * time.Second
+
+	reconnectMinInterval = 500 * time.Millisecond
+	reconnectMaxInterval = 30 * time.Second
+)
+
+// Connection represents a supervised connection to RabbitMQ, when the connection is closed by the broker or the
+// network it is re-dialed and the topology is declared again. Messages are published on a dedicated channel using
+// publisher confirms.
+type Connection struct {
+	url    string
+	setup  func(ch *amqp.Channel) error
+	logger *zap.Logger
+
+	mu   sync.RWMutex
+	conn *amqp.Connection
+	pub  *publisher
+
+	closeOnce sync.Once
+	closeC    chan struct{}
+	doneC     chan struct{}
+}
+
+// NewConnection dials url and calls setup to declare the exchanges and queues, setup is called again every time
+// the connection is reestablished.
+func NewConnection(url string, setup func(ch *amqp.Channel) error, logger *zap.Logger) (*Connection, error) {
+	c := &Connection{
+		url:    url,
+		setup:  setup,
+		logger: logger,
+		closeC: make(chan struct{}),
+		doneC:  make(chan struct{}),
+	}
+
+	if err := c.dial(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "dial")
+	}
+
+	go c.supervise()
+
+	return c, nil
+}
+
+// Channel opens a new channel on the current connection, it fails while reconnecting.
+func (c *Connection) Channel() (*amqp.Channel, error) {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conn.Channel")
+	}
+
+	return ch, nil
+}
+
+// Publish publishes msg and waits for the broker to confirm it.
+func (c *Connection) Publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	c.mu.RLock()
+	pub := c.pub
+	c.mu.RUnlock()
+
+	if err := pub.publish(ctx, exchange, routingKey, msg); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "publish")
+	}
+
+	return nil
+}
+
+// Close stops reconnecting and closes the connection, calling it again does nothing.
+func (c *Connection) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closeC)
+		<-c.doneC
+
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+
+		_ = c.conn.Close()
+	})
+}
+
+func (c *Connection) dial() error {
+	conn, err := amqp.Dial(c.url)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "amqp.Dial")
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conn.Channel")
+	}
+
+	if err := c.setup(ch); err != nil {
+		_ = conn.Close()
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "setup")
+	}
+
+	pub, err := newPublisher(ch)
+	if err != nil {
+		_ = conn.Close()
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newPublisher")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conn = conn
+	c.pub = pub
+
+	return nil
+}
+
+// supervise re-dials when the connection is closed, and reopens the publishing channel when only that one is
+// closed, until Close is called.
+func (c *Connection) supervise() {
+	defer close(c.doneC)
+
+	for {
+		c.mu.RLock()
+		conn, pub := c.conn, c.pub
+		c.mu.RUnlock()
+
+		connClosedC := conn.NotifyClose(make(chan *amqp.Error, 1))
+		chClosedC := pub.ch.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case <-c.closeC:
+			return
+		case err := <-connClosedC:
+			c.logger.Error("Connection closed, reconnecting", zap.Error(err))
+
+			if !c.reconnect() {
+				return
+			}
+		case err := <-chClosedC:
+			c.logger.Error("Publishing channel closed, reopening", zap.Error(err))
+
+			if err := c.reopen(conn); err != nil {
+				c.logger.Error("Couldn't reopen channel, reconnecting", zap.Error(err))
+
+				// Closing the connection is handled in the next iteration.
+				_ = conn.Close()
+			}
+		}
+	}
+}
+
+// reconnect dials until it succeeds, it returns false when Close is called before that.
+func (c *Connection) reconnect() bool {
+	interval := reconnectMinInterval
+
+	for {
+		select {
+		case <-c.closeC:
+			return false
+		case <-time.After(interval):
+		}
+
+		err := c.dial()
+		if err == nil {
+			c.logger.Info("Reconnected")
+
+			return true
+		}
+
+		c.logger.Error("Couldn't reconnect", zap.Error(err), zap.Duration("retry", interval))
+
+		interval *= 2
+		if interval > reconnectMaxInterval {
+			interval = reconnectMaxInterval
+		}
+	}
+}
+
+func (c *Connection) reopen(conn *amqp.Connection) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conn.Channel")
+	}
+
+	pub, err := newPublisher(ch)
+	if err != nil {
+		_ = ch.Close()
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newPublisher")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pub = pub
+
+	return nil
+}
+
+// publisher publishes messages on a channel in confirm mode, confirmations are matched to the published messages
+// using their delivery tag.
+type publisher struct {
+	ch *amqp.Channel
+
+	mu      sync.Mutex
+	tag     uint64
+	pending map[uint64]chan bool
+	closed  bool
+}
+
+func newPublisher(ch *amqp.Channel) (*publisher, error) {
+	if err := ch.Confirm(false); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "ch.Confirm")
+	}
+
+	p := &publisher{
+		ch:      ch,
+		pending: make(map[uint64]chan bool),
+	}
+
+	go p.confirm(ch.NotifyPublish(make(chan amqp.Confirmation, 64)))
+
+	return p, nil
+}
+
+// confirm notifies the publishers waiting for confirmations, when the channel is closed messages not confirmed yet
+// are considered failed.
+func (p *publisher) confirm(confirmsC <-chan amqp.Confirmation) {
+	for c := range confirmsC {
+		p.mu.Lock()
+		waitC, ok := p.pending[c.DeliveryTag]
+		delete(p.pending, c.DeliveryTag)
+		p.mu.Unlock()
+
+		if ok {
+			waitC <- c.Ack
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for tag, waitC := range p.pending {
+		close(waitC)
+		delete(p.pending, tag)
+	}
+
+	p.closed = true
+}
+
+func (p *publisher) publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	waitC := make(chan bool, 1)
+
+	// Delivery tags are assigned by the broker in the same order messages are published, publishing while holding
+	// the lock keeps both in sync.
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+		return internal.NewErrorf(internal.ErrorCodeUnkown, "channel closed")
+	}
+
+	if err := p.ch.Publish(exchange, routingKey, false, false, msg); err != nil {
+		p.mu.Unlock()
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "ch.Publish")
+	}
+
+	p.tag++
+	tag := p.tag
+	p.pending[tag] = waitC
+
+	p.mu.Unlock()
+
+	timer := time.NewTimer(confirmTimeout)
+	defer timer.Stop()
+
+	select {
+	case ack, ok := <-waitC:
+		if !ok {
+			return internal.NewErrorf(internal.ErrorCodeUnkown, "channel closed before confirming")
+		}
+
+		if !ack {
+			return internal.NewErrorf(internal.ErrorCodeUnkown, "message rejected by the broker")
+		}
+
+		return nil
+	case <-timer.C:
+		p.forget(tag)
+
+		return internal.NewErrorf(internal.ErrorCodeUnkown, "confirmation timed out")
+	case <-ctx.Done():
+		p.forget(tag)
+
+		return internal.WrapErrorf(ctx.Err(), internal.ErrorCodeUnkown, "ctx.Done")
+	}
+}
+
+func (p *publisher) forget(tag uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.pending, tag)
+}
@@ -0,0 +1,142 @@
+package rabbitmq
+
+import (
+	"context"
+	"time"
+
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+)
+
+// resubscribeInterval is how long to wait before consuming again after the channel is closed.
+const resubscribeInterval = time.Second
+
+// Consumer represents the consumer receiving Task events from the tasks exchange.
+type Consumer struct {
+	conn       *Connection
+	deadLetter *DeadLetter
+	queue      string
+	name       string
+	logger     *zap.Logger
+}
+
+// NewConsumer instantiates the Consumer. queue is the durable queue bound to the tasks exchange, it is shared by
+// all the instances of the same service so messages published while they are down are not lost; name identifies the
+// consumer in the channel.
+func NewConsumer(conn *Connection, deadLetter *DeadLetter, queue, name string, logger *zap.Logger) *Consumer {
+	return &Consumer{
+		conn:       conn,
+		deadLetter: deadLetter,
+		queue:      queue,
+		name:       name,
+		logger:     logger,
+	}
+}
+
+// Consume calls handle for every message until ctx is done. Messages failing are published to the dead letter
+// exchange, when that fails they are rejected so the broker dead letters them instead. When the channel is closed,
+// for example because the connection is reestablished, it consumes again using a new channel.
+func (c *Consumer) Consume(ctx context.Context, handle func(ctx context.Context, msg event.Message) error) error {
+	for {
+		err := c.consume(ctx, handle)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		c.logger.Error("Consumer stopped, consuming again", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(resubscribeInterval):
+		}
+	}
+}
+
+func (c *Consumer) consume(ctx context.Context, handle func(ctx context.Context, msg event.Message) error) error {
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conn.Channel")
+	}
+	defer ch.Close()
+
+	if err := ch.Qos(
+		1,     //prefetch Count
+		0,     //prefetch Size
+		false, //global
+	); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "ch.Qos")
+	}
+
+	_, err = ch.QueueDeclare(
+		c.queue, //name
+		true,    //durable
+		false,   //delete when unused
+		false,   //exclusive
+		false,   //no-wait
+		amqp.Table{
+			"x-dead-letter-exchange": DeadLetterExchange,
+		}, //arguments
+	)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "ch.QueueDeclare")
+	}
+
+	err = ch.QueueBind(
+		c.queue,         //queue name
+		"tasks.event.*", //routing key
+		"tasks",         //exchange
+		false,
+		nil,
+	)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "ch.QueueBind")
+	}
+
+	msgs, err := ch.Consume(
+		c.queue, //queue
+		c.name,  //consumer
+		false,   //auto-ack
+		false,   //exclusive
+		false,   //no-local
+		false,   //no-wait
+		nil,     //args
+	)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "ch.Consume")
+	}
+
+	handleCtx := context.WithoutCancel(ctx)
+
+	doneC := make(chan struct{})
+	defer close(doneC)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Cancelling closes msgs after the messages already delivered are received.
+			_ = ch.Cancel(c.name, false)
+		case <-doneC:
+		}
+	}()
+
+	for msg := range msgs {
+		if err := handle(handleCtx, event.Message{ContentType: msg.ContentType, Headers: tableCarrier(msg.Headers), Body: msg.Body}); err != nil {
+			c.logger.Error("Couldn't consume, dead lettering", zap.Error(err))
+
+			if err := c.deadLetter.Send(handleCtx, msg, err); err != nil {
+				c.logger.Error("Couldn't dead letter, rejecting", zap.Error(err))
+				_ = msg.Nack(false, false)
+
+				continue
+			}
+		}
+
+		_ = msg.Ack(false)
+	}
+
+	return internal.NewErrorf(internal.ErrorCodeUnkown, "channel closed")
+}
@@ -0,0 +1,198 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+)
+
+const (
+	// DeadLetterExchange is the exchange receiving messages that couldn't be processed, queues consuming task
+	// events should use it as their "x-dead-letter-exchange".
+	DeadLetterExchange = "tasks.dlx"
+
+	// DeadLetterQueue is the queue bound to DeadLetterExchange holding all dead letters.
+	DeadLetterQueue = "tasks.dead-letter"
+
+	headerDeadLetterError = "x-dead-letter-error"
+)
+
+// DeadLetter represents the queue holding messages that couldn't be processed by consumers.
+type DeadLetter struct {
+	conn *Connection
+}
+
+// NewDeadLetter instantiates the DeadLetter repository, the exchange and queue must be declared already.
+func NewDeadLetter(conn *Connection) *DeadLetter {
+	return &DeadLetter{
+		conn: conn,
+	}
+}
+
+// Send publishes msg to the dead letter exchange including the reason it failed, the original routing key is kept
+// so it can be replayed later. The original message must be acknowledged afterwards.
+func (d *DeadLetter) Send(ctx context.Context, msg amqp.Delivery, reason error) error {
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	headers[headerDeadLetterError] = reason.Error()
+
+	err := d.conn.Publish(ctx,
+		DeadLetterExchange, //exchange
+		msg.RoutingKey,     //routing key
+		amqp.Publishing{
+			Headers:      headers,
+			AppId:        msg.AppId,
+			ContentType:  msg.ContentType,
+			MessageId:    msg.MessageId,
+			Type:         msg.Type,
+			DeliveryMode: amqp.Persistent,
+			Body:         msg.Body,
+			Timestamp:    time.Now(),
+		})
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conn.Publish")
+	}
+
+	return nil
+}
+
+// List returns up to limit dead letters without removing them from the queue.
+func (d *DeadLetter) List(_ context.Context, limit int) ([]event.DeadLetter, error) {
+	ch, err := d.conn.Channel()
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conn.Channel")
+	}
+	defer ch.Close()
+
+	msgs, err := get(ch, limit)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "get")
+	}
+
+	res := make([]event.DeadLetter, len(msgs))
+	for i, msg := range msgs {
+		res[i] = newDeadLetter(msg)
+	}
+
+	if len(msgs) > 0 {
+		// Requeue everything at once, messages are not redelivered to this channel while they are unacknowledged.
+		if err := ch.Nack(msgs[len(msgs)-1].DeliveryTag, true, true); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "ch.Nack")
+		}
+	}
+
+	return res, nil
+}
+
+// Replay publishes up to limit dead letters back to the tasks exchange using their original routing key and headers,
+// like the propagated trace context, replayed dead letters are removed from the queue.
+func (d *DeadLetter) Replay(ctx context.Context, limit int) (int, error) {
+	ch, err := d.conn.Channel()
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conn.Channel")
+	}
+	defer ch.Close()
+
+	msgs, err := get(ch, limit)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "get")
+	}
+
+	for i, msg := range msgs {
+		err := d.conn.Publish(ctx,
+			"tasks",        //exchange
+			msg.RoutingKey, //routing key
+			amqp.Publishing{
+				Headers:      replayHeaders(msg.Headers),
+				AppId:        msg.AppId,
+				ContentType:  msg.ContentType,
+				MessageId:    msg.MessageId,
+				Type:         msg.Type,
+				DeliveryMode: amqp.Persistent,
+				Body:         msg.Body,
+				Timestamp:    time.Now(),
+			})
+		if err != nil {
+			// Dead letters not replayed are requeued when the channel is closed.
+			return i, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conn.Publish")
+		}
+
+		if err := msg.Ack(false); err != nil {
+			return i, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "msg.Ack")
+		}
+	}
+
+	return len(msgs), nil
+}
+
+// replayHeaders returns the original headers of a dead letter, the ones describing why it failed are removed so
+// the replayed message is not reported as failed again.
+func replayHeaders(headers amqp.Table) amqp.Table {
+	res := amqp.Table{}
+	for k, v := range headers {
+		res[k] = v
+	}
+
+	delete(res, headerDeadLetterError)
+	delete(res, "x-death")
+	delete(res, "x-first-death-exchange")
+	delete(res, "x-first-death-queue")
+	delete(res, "x-first-death-reason")
+
+	return res
+}
+
+func get(ch *amqp.Channel, limit int) ([]amqp.Delivery, error) {
+	var res []amqp.Delivery
+
+	for len(res) < limit {
+		msg, ok, err := ch.Get(DeadLetterQueue, false)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "ch.Get")
+		}
+
+		if !ok {
+			break
+		}
+
+		res = append(res, msg)
+	}
+
+	return res, nil
+}
+
+func newDeadLetter(msg amqp.Delivery) event.DeadLetter {
+	res := event.DeadLetter{
+		ID:          msg.MessageId,
+		Destination: msg.RoutingKey,
+		FailedAt:    msg.Timestamp,
+		ContentType: msg.ContentType,
+		Body:        msg.Body,
+	}
+
+	if reason, ok := msg.Headers[headerDeadLetterError].(string); ok {
+		res.Error = reason
+	}
+
+	// Messages rejected without requeueing are dead lettered by the broker itself, in that case the reason is
+	// defined in the "x-death" header.
+	if deaths, ok := msg.Headers["x-death"].([]interface{}); ok && len(deaths) > 0 && res.Error == "" {
+		if death, ok := deaths[0].(amqp.Table); ok {
+			res.Error = fmt.Sprintf("%v", death["reason"])
+
+			if t, ok := death["time"].(time.Time); ok {
+				res.FailedAt = t
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,29 @@
+package rabbitmq
+
+import (
+	"testing"
+
+	"github.com/streadway/amqp"
+)
+
+func TestReplayHeaders(t *testing.T) {
+	t.Parallel()
+
+	headers := amqp.Table{
+		"traceparent":         "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"tracestate":          "vendor=value",
+		headerDeadLetterError: "failed",
+		"x-death":             []interface{}{amqp.Table{"reason": "rejected"}},
+		"x-first-death-queue": "tasks.indexer",
+	}
+
+	got := replayHeaders(headers)
+
+	if len(got) != 2 || got["traceparent"] != headers["traceparent"] || got["tracestate"] != headers["tracestate"] {
+		t.Fatalf("expected only the trace context to be kept, got %v", got)
+	}
+
+	if _, ok := headers[headerDeadLetterError]; !ok {
+		t.Fatalf("expected the dead letter headers to be left unchanged")
+	}
+}
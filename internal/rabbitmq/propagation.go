@@ -0,0 +1,31 @@
+package rabbitmq
+
+import (
+	"github.com/streadway/amqp"
+)
+
+// tableCarrier adapts the headers of a message so the trace context is injected into and extracted from them.
+type tableCarrier amqp.Table
+
+// Get returns the value of the header named key, headers that are not strings are ignored.
+func (c tableCarrier) Get(key string) string {
+	res, _ := c[key].(string)
+
+	return res
+}
+
+// Set replaces the header named key.
+func (c tableCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Keys returns the names of the headers.
+func (c tableCarrier) Keys() []string {
+	res := make([]string, 0, len(c))
+
+	for k := range c {
+		res = append(res, k)
+	}
+
+	return res
+}
@@ -1,50 +1,52 @@
 package rabbitmq
 
 import (
-	"bytes"
 	"context"
-	"encoding/gob"
 	"time"
 
 	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
 	"github.com/streadway/amqp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const otelName = "github.com/sanLimbu/todo-api/internal/rabbitmq"
 
 //Task represents the repository used for publishing Task records.
 type Task struct {
-	ch *amqp.Channel
+	conn       *Connection
+	serializer event.Serializer
 }
 
-//NewTask instantiates the Task repository
-func NewTask(channel *amqp.Channel) (*Task, error) {
+//NewTask instantiates the Task repository, messages are published using publisher confirms.
+func NewTask(conn *Connection, serializer event.Serializer) *Task {
 	return &Task{
-		ch: channel,
-	}, nil
+		conn:       conn,
+		serializer: serializer,
+	}
 }
 
 //Created publishes a message indicating a task was created.
 func (t *Task) Created(ctx context.Context, task internal.Task) error {
-	return t.publish(ctx, "Task.Created", "tasks.event.created", task)
+	return t.publish(ctx, "Task.Created", event.TypeTaskCreated, task)
 }
 
 //Deleted publishes a message indicating a task was deleted
-func (t *Task) Deleted(ctx context.Context, id string) error {
-	return t.publish(ctx, "Task.Deleted", "tasks.event.deleted", id)
+func (t *Task) Deleted(ctx context.Context, id string, version int64) error {
+	return t.publish(ctx, "Task.Deleted", event.TypeTaskDeleted, internal.Task{ID: id, Version: version})
 }
 
 //Updated publishes a message indicating a task was updated
 func (t *Task) Updated(ctx context.Context, task internal.Task) error {
-	return t.publish(ctx, "Task.Updated", "tasks.event.updated", task)
+	return t.publish(ctx, "Task.Updated", event.TypeTaskUpdated, task)
 }
 
-func (t *Task) publish(ctx context.Context, spanName, routingKey string, e interface{}) error {
+func (t *Task) publish(ctx context.Context, spanName, routingKey string, task internal.Task) error {
 
-	_, span := otel.Tracer(otelName).Start(ctx, spanName)
+	ctx, span := otel.Tracer(otelName).Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindProducer))
 
 	//ctx, span := trace.SpanFromContext(ctx).Tracer().Start(ctx, spanName)
 	defer span.End()
@@ -59,25 +61,32 @@ func (t *Task) publish(ctx context.Context, spanName, routingKey string, e inter
 			Value: attribute.StringValue(routingKey),
 		},
 	)
-	var b bytes.Buffer
-	if err := gob.NewEncoder(&b).Encode(e); err != nil {
-		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "gob.Encode")
+	evt := event.NewTask(ctx, routingKey, task)
+
+	b, err := t.serializer.Marshal(evt)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "serializer.Marshal")
 	}
 
-	err := t.ch.Publish(
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, tableCarrier(headers))
+
+	err = t.conn.Publish(ctx,
 		"tasks",    //exchange
 		routingKey, //routing key
-		false,      //mandatory
-		false,      //immediate
 		amqp.Publishing{
-			AppId:       "tasks-rest-server",
-			ContentType: "application/x-encoding-gob",
-			Body:        b.Bytes(),
-			Timestamp:   time.Now(),
+			AppId:        "tasks-rest-server",
+			ContentType:  t.serializer.ContentType(),
+			Headers:      headers,
+			MessageId:    evt.ID,
+			Type:         evt.Type,
+			DeliveryMode: amqp.Persistent,
+			Body:         b,
+			Timestamp:    time.Now(),
 		})
 
 	if err != nil {
-		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "ch.publish")
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "conn.Publish")
 	}
 	return nil
 }
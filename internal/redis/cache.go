@@ -0,0 +1,122 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/cache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// cacheKeyPrefix keeps cached values apart from the streams stored in the same database.
+const cacheKeyPrefix = "cache:"
+
+// incrementScript increments the number only when it exists, INCRBY would create it otherwise.
+var incrementScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return false
+end
+return redis.call("INCRBY", KEYS[1], ARGV[1])
+`)
+
+// Cache represents the cache backend storing values in Redis.
+type Cache struct {
+	client *redis.Client
+}
+
+// NewCache instantiates the Redis Cache.
+func NewCache(client *redis.Client) *Cache {
+	return &Cache{
+		client: client,
+	}
+}
+
+// Get returns the cached value.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	defer newCacheSpan(ctx, "Cache.Get", "GET").End()
+
+	value, err := c.client.Get(ctx, cacheKeyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, cache.ErrMiss
+		}
+
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Get")
+	}
+
+	return value, nil
+}
+
+// Set caches the value.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	defer newCacheSpan(ctx, "Cache.Set", "SET").End()
+
+	if err := c.client.Set(ctx, cacheKeyPrefix+key, value, expiration).Err(); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Set")
+	}
+
+	return nil
+}
+
+// Add caches the value when key is not cached.
+func (c *Cache) Add(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	defer newCacheSpan(ctx, "Cache.Add", "SETNX").End()
+
+	ok, err := c.client.SetNX(ctx, cacheKeyPrefix+key, value, expiration).Result()
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.SetNX")
+	}
+
+	if !ok {
+		return cache.ErrNotStored
+	}
+
+	return nil
+}
+
+// Increment adds delta to the cached number.
+func (c *Cache) Increment(ctx context.Context, key string, delta uint64) (uint64, error) {
+	defer newCacheSpan(ctx, "Cache.Increment", "EVALSHA").End()
+
+	n, err := incrementScript.Run(ctx, c.client, []string{cacheKeyPrefix + key}, delta).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, cache.ErrMiss
+		}
+
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "incrementScript.Run")
+	}
+
+	return uint64(n), nil
+}
+
+// Delete removes the key.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	defer newCacheSpan(ctx, "Cache.Delete", "DEL").End()
+
+	if err := c.client.Del(ctx, cacheKeyPrefix+key).Err(); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Del")
+	}
+
+	return nil
+}
+
+func newCacheSpan(ctx context.Context, name, statement string) trace.Span {
+	_, span := otel.Tracer(otelName).Start(ctx, name)
+
+	span.SetAttributes(
+		semconv.DBSystemRedis,
+		attribute.KeyValue{
+			Key:   semconv.DBStatementKey,
+			Value: attribute.StringValue(statement),
+		},
+	)
+
+	return span
+}
@@ -0,0 +1,259 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+)
+
+// claimMinIdle is how long an entry must be pending in another consumer before it's claimed, that consumer is
+// assumed to have crashed.
+const claimMinIdle = time.Minute
+
+// Message represents an entry read from Stream.
+type Message struct {
+	ID          string
+	ContentType string
+	Payload     []byte
+}
+
+// Consumer reads Task events from Stream as part of a consumer group. Entries must be acknowledged after they are
+// handled, entries pending for too long in other consumers of the same group are claimed. Consumer is not safe for
+// concurrent use.
+type Consumer struct {
+	client     *redis.Client
+	deadLetter *DeadLetter
+	group      string
+	name       string
+	claimStart string
+	logger     *zap.Logger
+}
+
+// NewConsumer instantiates the Consumer, name identifies the consumer in group and it must be unique.
+func NewConsumer(client *redis.Client, deadLetter *DeadLetter, group, name string, logger *zap.Logger) *Consumer {
+	return &Consumer{
+		client:     client,
+		deadLetter: deadLetter,
+		group:      group,
+		name:       name,
+		claimStart: "0-0",
+		logger:     logger,
+	}
+}
+
+// Consume creates the consumer group and calls handle for every entry until ctx is done. Entries failing are added
+// to the dead letter stream, entries are acknowledged only after they are handled or dead lettered.
+func (c *Consumer) Consume(ctx context.Context, handle func(ctx context.Context, msg event.Message) error) error {
+	if err := c.CreateGroup(ctx); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "CreateGroup")
+	}
+
+	handleCtx := context.WithoutCancel(ctx)
+
+	for ctx.Err() == nil {
+		msgs, err := c.Read(ctx, 10, 5*time.Second)
+		if err != nil {
+			if ctx.Err() == nil {
+				c.logger.Error("Couldn't read", zap.Error(err))
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+			}
+
+			continue
+		}
+
+		for _, msg := range msgs {
+			if err := handle(handleCtx, event.Message{ContentType: msg.ContentType, Body: msg.Payload}); err != nil {
+				c.logger.Error("Couldn't consume, dead lettering", zap.Error(err))
+
+				// Entries not acknowledged are claimed again later.
+				if err := c.deadLetter.Send(handleCtx, Stream, msg, err); err != nil {
+					c.logger.Error("Couldn't dead letter", zap.Error(err))
+
+					continue
+				}
+			}
+
+			if err := c.Ack(handleCtx, msg.ID); err != nil {
+				c.logger.Error("Couldn't ack", zap.Error(err))
+			}
+		}
+	}
+
+	return nil
+}
+
+// CreateGroup creates the stream and the consumer group when they don't exist, new groups read all the events
+// still kept in the stream.
+func (c *Consumer) CreateGroup(ctx context.Context) error {
+	err := c.client.XGroupCreateMkStream(ctx, Stream, c.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.XGroupCreateMkStream")
+	}
+
+	return nil
+}
+
+// Read returns up to count entries, entries claimed from crashed consumers are returned first and when there are
+// none it blocks up to block waiting for new ones.
+func (c *Consumer) Read(ctx context.Context, count int64, block time.Duration) ([]Message, error) {
+	claimed, err := c.autoClaim(ctx, count)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "autoClaim")
+	}
+
+	if len(claimed) > 0 {
+		return newMessages(claimed), nil
+	}
+
+	streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.group,
+		Consumer: c.name,
+		Streams:  []string{Stream, ">"},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.XReadGroup")
+	}
+
+	var res []Message
+	for _, stream := range streams {
+		res = append(res, newMessages(stream.Messages)...)
+	}
+
+	return res, nil
+}
+
+// autoClaim claims the entries pending in other consumers for longer than claimMinIdle. The command is issued directly
+// because go-redis v8 only parses the reply of Redis 6.2, Redis 7 replies with a third element listing the IDs of the
+// pending entries that were deleted from the stream.
+func (c *Consumer) autoClaim(ctx context.Context, count int64) ([]redis.XMessage, error) {
+	reply, err := c.client.Do(ctx, "XAUTOCLAIM", Stream, c.group, c.name,
+		claimMinIdle.Milliseconds(), c.claimStart, "COUNT", count).Slice()
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Do")
+	}
+
+	if len(reply) < 2 {
+		return nil, internal.NewErrorf(internal.ErrorCodeUnkown, "unexpected XAUTOCLAIM reply length %d", len(reply))
+	}
+
+	start, ok := reply[0].(string)
+	if !ok {
+		return nil, internal.NewErrorf(internal.ErrorCodeUnkown, "unexpected XAUTOCLAIM cursor %T", reply[0])
+	}
+
+	entries, ok := reply[1].([]interface{})
+	if !ok {
+		return nil, internal.NewErrorf(internal.ErrorCodeUnkown, "unexpected XAUTOCLAIM entries %T", reply[1])
+	}
+
+	var (
+		res     []redis.XMessage
+		deleted []string
+	)
+
+	for _, entry := range entries {
+		msg, err := newXMessage(entry)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "newXMessage")
+		}
+
+		// Redis 6.2 returns entries deleted from the stream without values, they are kept pending until acknowledged.
+		if msg.Values == nil {
+			deleted = append(deleted, msg.ID)
+
+			continue
+		}
+
+		res = append(res, msg)
+	}
+
+	if len(deleted) > 0 {
+		if err := c.Ack(ctx, deleted...); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "Ack")
+		}
+	}
+
+	c.claimStart = start
+
+	return res, nil
+}
+
+// Ack acknowledges the entries so they are not claimed by other consumers.
+func (c *Consumer) Ack(ctx context.Context, ids ...string) error {
+	if err := c.client.XAck(ctx, Stream, c.group, ids...).Err(); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.XAck")
+	}
+
+	return nil
+}
+
+func newMessages(msgs []redis.XMessage) []Message {
+	res := make([]Message, len(msgs))
+
+	for i, msg := range msgs {
+		payload, _ := msg.Values[fieldEvent].(string)
+		contentType, _ := msg.Values[fieldContentType].(string)
+
+		res[i] = Message{
+			ID:          msg.ID,
+			ContentType: contentType,
+			Payload:     []byte(payload),
+		}
+	}
+
+	return res
+}
+
+// newXMessage parses an entry of the XAUTOCLAIM reply, an array holding the ID and the list of fields and values.
+func newXMessage(entry interface{}) (redis.XMessage, error) {
+	parts, ok := entry.([]interface{})
+	if !ok || len(parts) != 2 {
+		return redis.XMessage{}, internal.NewErrorf(internal.ErrorCodeUnkown, "unexpected entry %T", entry)
+	}
+
+	id, ok := parts[0].(string)
+	if !ok {
+		return redis.XMessage{}, internal.NewErrorf(internal.ErrorCodeUnkown, "unexpected entry ID %T", parts[0])
+	}
+
+	res := redis.XMessage{ID: id}
+
+	if parts[1] == nil {
+		return res, nil
+	}
+
+	fields, ok := parts[1].([]interface{})
+	if !ok || len(fields)%2 != 0 {
+		return redis.XMessage{}, internal.NewErrorf(internal.ErrorCodeUnkown, "unexpected entry values %T", parts[1])
+	}
+
+	res.Values = make(map[string]interface{}, len(fields)/2)
+
+	for i := 0; i < len(fields); i += 2 {
+		key, ok := fields[i].(string)
+		if !ok {
+			return redis.XMessage{}, internal.NewErrorf(internal.ErrorCodeUnkown, "unexpected field %T", fields[i])
+		}
+
+		res.Values[key] = fields[i+1]
+	}
+
+	return res, nil
+}
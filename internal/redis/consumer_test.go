@@ -0,0 +1,185 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+	internalredis "github.com/sanLimbu/todo-api/internal/redis"
+)
+
+func newClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return srv, client
+}
+
+func newConsumer(client *redis.Client, name string) *internalredis.Consumer {
+	return internalredis.NewConsumer(client, internalredis.NewDeadLetter(client), "indexer", name, zap.NewNop())
+}
+
+func publish(t *testing.T, client *redis.Client, id string) {
+	t.Helper()
+
+	if err := internalredis.NewTask(client, event.JSON{}).Created(context.Background(), internal.Task{ID: id}); err != nil {
+		t.Fatalf("Created: %s", err)
+	}
+}
+
+func TestConsumer_Read(t *testing.T) {
+	t.Parallel()
+
+	_, client := newClient(t)
+	ctx := context.Background()
+
+	consumer := newConsumer(client, "consumer-1")
+
+	if err := consumer.CreateGroup(ctx); err != nil {
+		t.Fatalf("CreateGroup: %s", err)
+	}
+
+	publish(t, client, "task-1")
+
+	msgs, err := consumer.Read(ctx, 10, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	evt, err := event.Unmarshal(event.Message{ContentType: msgs[0].ContentType, Body: msgs[0].Payload})
+	if err != nil {
+		t.Fatalf("Unmarshal: %s", err)
+	}
+
+	if evt.Type != event.TypeTaskCreated || evt.Data.ID != "task-1" {
+		t.Fatalf("unexpected event %+v", evt)
+	}
+
+	if err := consumer.Ack(ctx, msgs[0].ID); err != nil {
+		t.Fatalf("Ack: %s", err)
+	}
+
+	pending, err := client.XPending(ctx, internalredis.Stream, "indexer").Result()
+	if err != nil {
+		t.Fatalf("XPending: %s", err)
+	}
+
+	if pending.Count != 0 {
+		t.Fatalf("expected no pending entries, got %d", pending.Count)
+	}
+}
+
+func TestConsumer_ReadClaimsIdleEntries(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newClient(t)
+	ctx := context.Background()
+
+	crashed := newConsumer(client, "consumer-1")
+
+	if err := crashed.CreateGroup(ctx); err != nil {
+		t.Fatalf("CreateGroup: %s", err)
+	}
+
+	publish(t, client, "task-1")
+
+	msgs, err := crashed.Read(ctx, 10, time.Millisecond)
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Read: expected 1 message, got %d: %v", len(msgs), err)
+	}
+
+	// Entries are not claimed while they are pending for less than a minute.
+	consumer := newConsumer(client, "consumer-2")
+
+	msgs, err = consumer.Read(ctx, 10, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+
+	if len(msgs) != 0 {
+		t.Fatalf("expected no messages, got %d", len(msgs))
+	}
+
+	srv.SetTime(time.Now().Add(2 * time.Minute))
+
+	msgs, err = consumer.Read(ctx, 10, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 claimed message, got %d", len(msgs))
+	}
+}
+
+func TestConsumer_Consume(t *testing.T) {
+	t.Parallel()
+
+	_, client := newClient(t)
+
+	publish(t, client, "task-1")
+	publish(t, client, "task-2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var handled []string
+
+	handle := func(_ context.Context, msg event.Message) error {
+		evt, err := event.Unmarshal(msg)
+		if err != nil {
+			return err
+		}
+
+		handled = append(handled, evt.Data.ID)
+
+		if len(handled) == 2 {
+			cancel()
+
+			return errors.New("failed")
+		}
+
+		return nil
+	}
+
+	if err := newConsumer(client, "consumer-1").Consume(ctx, handle); err != nil {
+		t.Fatalf("Consume: %s", err)
+	}
+
+	if len(handled) != 2 || handled[0] != "task-1" || handled[1] != "task-2" {
+		t.Fatalf("unexpected handled tasks %v", handled)
+	}
+
+	dls, err := internalredis.NewDeadLetter(client).List(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("List: %s", err)
+	}
+
+	if len(dls) != 1 || dls[0].Error != "failed" || dls[0].Destination != internalredis.Stream {
+		t.Fatalf("unexpected dead letters %+v", dls)
+	}
+
+	pending, err := client.XPending(context.Background(), internalredis.Stream, "indexer").Result()
+	if err != nil {
+		t.Fatalf("XPending: %s", err)
+	}
+
+	if pending.Count != 0 {
+		t.Fatalf("expected no pending entries, got %d", pending.Count)
+	}
+}
@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+)
+
+// DeadLetterStream is the stream holding messages that couldn't be processed.
+const DeadLetterStream = "tasks.dead-letter"
+
+// DeadLetter represents the stream holding messages that couldn't be processed by consumers.
+type DeadLetter struct {
+	client *redis.Client
+}
+
+// NewDeadLetter instantiates the DeadLetter repository.
+func NewDeadLetter(client *redis.Client) *DeadLetter {
+	return &DeadLetter{
+		client: client,
+	}
+}
+
+// Send adds the message read from stream to the dead letter stream including the reason it failed, the dead letter
+// stream is trimmed the same way Stream is.
+func (d *DeadLetter) Send(ctx context.Context, stream string, msg Message, reason error) error {
+	err := d.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: DeadLetterStream,
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"destination":    stream,
+			"error":          reason.Error(),
+			"failed_at":      time.Now().UTC().Format(time.RFC3339Nano),
+			fieldContentType: msg.ContentType,
+			"body":           msg.Payload,
+		},
+	}).Err()
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.XAdd")
+	}
+
+	return nil
+}
+
+// List returns up to limit dead letters, oldest first, without removing them.
+func (d *DeadLetter) List(ctx context.Context, limit int) ([]event.DeadLetter, error) {
+	msgs, err := d.client.XRangeN(ctx, DeadLetterStream, "-", "+", int64(limit)).Result()
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.XRangeN")
+	}
+
+	res := make([]event.DeadLetter, len(msgs))
+	for i, msg := range msgs {
+		res[i] = newDeadLetter(msg)
+	}
+
+	return res, nil
+}
+
+// Replay adds up to limit dead letters back to their original stream, replayed dead letters are removed from the
+// dead letter stream.
+func (d *DeadLetter) Replay(ctx context.Context, limit int) (int, error) {
+	dls, err := d.List(ctx, limit)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "List")
+	}
+
+	for i, dl := range dls {
+		err := d.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: dl.Destination,
+			MaxLen: streamMaxLen,
+			Approx: true,
+			Values: map[string]interface{}{
+				fieldEvent:       dl.Body,
+				fieldContentType: dl.ContentType,
+			},
+		}).Err()
+		if err != nil {
+			return i, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.XAdd")
+		}
+
+		if err := d.client.XDel(ctx, DeadLetterStream, dl.ID).Err(); err != nil {
+			return i, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.XDel")
+		}
+	}
+
+	return len(dls), nil
+}
+
+func newDeadLetter(msg redis.XMessage) event.DeadLetter {
+	value := func(key string) string {
+		s, _ := msg.Values[key].(string)
+		return s
+	}
+
+	failedAt, _ := time.Parse(time.RFC3339Nano, value("failed_at"))
+
+	return event.DeadLetter{
+		ID:          msg.ID,
+		Destination: value("destination"),
+		Error:       value("error"),
+		FailedAt:    failedAt,
+		ContentType: value(fieldContentType),
+		Body:        []byte(value("body")),
+	}
+}
@@ -1,69 +1,91 @@
 package redis
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const otelName = "github.com/sanLimbu/todo-api/internal/redis"
 
-//Task represents the repository used for publishing Task records
+const (
+	// Stream is the stream receiving all Task events.
+	Stream = "tasks.events"
+
+	// streamMaxLen is the approximate number of events kept in Stream, older events are trimmed when publishing.
+	streamMaxLen = 100_000
+
+	fieldEvent       = "event"
+	fieldContentType = "content_type"
+)
+
+//Task represents the repository used for publishing Task records to a stream, events are kept until trimmed so
+//consumers that are down don't miss them.
 type Task struct {
-	client *redis.Client
+	client     *redis.Client
+	serializer event.Serializer
 }
 
 //NewTask instantiates the Task repository
-func NewTask(client *redis.Client) *Task {
+func NewTask(client *redis.Client, serializer event.Serializer) *Task {
 	return &Task{
-		client: client,
+		client:     client,
+		serializer: serializer,
 	}
 }
 
 // Created publishes a message indicating a task was created.
 func (t *Task) Created(ctx context.Context, task internal.Task) error {
-	return t.publish(ctx, "Task.Created", "tasks.event.created", task)
+	return t.publish(ctx, "Task.Created", event.TypeTaskCreated, task)
 }
 
 // Deleted publishes a message indicating a task was deleted.
-func (t *Task) Deleted(ctx context.Context, id string) error {
-	return t.publish(ctx, "Task.Deleted", "tasks.event.deleted", id)
+func (t *Task) Deleted(ctx context.Context, id string, version int64) error {
+	return t.publish(ctx, "Task.Deleted", event.TypeTaskDeleted, internal.Task{ID: id, Version: version})
 }
 
 // Updated publishes a message indicating a task was updated.
 func (t *Task) Updated(ctx context.Context, task internal.Task) error {
-	return t.publish(ctx, "Task.Updated", "tasks.event.updated", task)
+	return t.publish(ctx, "Task.Updated", event.TypeTaskUpdated, task)
 }
 
-func (t *Task) publish(ctx context.Context, spanName, channel string, event interface{}) error {
-	ctx, span := otel.Tracer(otelName).Start(ctx, spanName)
+func (t *Task) publish(ctx context.Context, spanName, eventType string, task internal.Task) error {
+	ctx, span := otel.Tracer(otelName).Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindProducer))
 	defer span.End()
 
 	span.SetAttributes(
 		semconv.DBSystemRedis,
 		attribute.KeyValue{
 			Key:   semconv.DBStatementKey,
-			Value: attribute.StringValue("PUBLISH"),
+			Value: attribute.StringValue("XADD"),
 		},
 	)
 
 	//-
 
-	var b bytes.Buffer
-
-	if err := json.NewEncoder(&b).Encode(event); err != nil {
-		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Encode")
+	// Stream entries don't have headers, the trace context is propagated in the envelope.
+	b, err := t.serializer.Marshal(event.NewTask(ctx, eventType, task))
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "serializer.Marshal")
 	}
 
-	res := t.client.Publish(ctx, channel, b.Bytes())
-	if err := res.Err(); err != nil {
-		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Publish")
+	err = t.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: Stream,
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			fieldEvent:       b,
+			fieldContentType: t.serializer.ContentType(),
+		},
+	}).Err()
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.XAdd")
 	}
 
 	return nil
@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+// unmatchedRoute is used as route of the requests not matching any, using their paths would create a new time series
+// for every path requested.
+const unmatchedRoute = "unmatched"
+
+// NewMetricsMiddleware records the duration of the requests by method, route pattern and status code.
+func NewMetricsMiddleware() func(next http.Handler) http.Handler {
+	duration, _ := otel.Meter(otelName).Float64Histogram("http.server.duration",
+		metric.WithDescription("Time spent serving HTTP requests, in seconds"),
+		metric.WithUnit("s"))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			// The route pattern is known once the request was routed.
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			duration.Record(r.Context(), time.Since(start).Seconds(), metric.WithAttributes(
+				semconv.HTTPMethodKey.String(r.Method),
+				semconv.HTTPRouteKey.String(route),
+				semconv.HTTPStatusCodeKey.Int(status)))
+		})
+	}
+}
@@ -110,9 +110,12 @@ func NewOpenAPI3() openapi3.T {
 						Ref: "#/components/schemas/Priority",
 					}).WithNullable().
 					WithProperty("from", openapi3.NewInt64Schema().
+						WithMin(0).
 						WithDefault(0)).
 					WithProperty("size", openapi3.NewInt64Schema().
-						WithDefault(10))),
+						WithMin(0).
+						WithDefault(10)).
+					WithProperty("cursor", openapi3.NewStringSchema())),
 		},
 	}
 
@@ -151,7 +154,8 @@ func NewOpenAPI3() openapi3.T {
 							},
 						},
 					}).
-					WithProperty("total", openapi3.NewInt64Schema()))),
+					WithProperty("total", openapi3.NewInt64Schema()).
+					WithProperty("next_cursor", openapi3.NewStringSchema()))),
 		},
 	}
 
@@ -20,34 +20,20 @@ const (
 
 //NewPriority covers the received domain type to a rest type, when the argument is unkown "none" is used.
 func NewPriority(p internal.Priority) Priority {
-	switch p {
-	case internal.PriorityNone:
+	if err := p.Validate(); err != nil {
 		return priorityNone
-	case internal.PriorityLow:
-		return priorityLow
-	case internal.PriorityMedium:
-		return priorityMedium
-	case internal.PriorityHigh:
-		return priorityHigh
-
 	}
-	return priorityNone
+	return Priority(p.String())
 }
 
 //Convert returns the domain type defining the internal representation, when priority is unkown "none" is used.
 
 func (p Priority) Convert() internal.Priority {
-	switch p {
-	case priorityNone:
+	res, err := internal.ParsePriority(string(p))
+	if err != nil {
 		return internal.PriorityNone
-	case priorityLow:
-		return internal.PriorityLow
-	case priorityMedium:
-		return internal.PriorityMedium
-	case priorityHigh:
-		return internal.PriorityHigh
 	}
-	return internal.PriorityNone
+	return res
 }
 
 //Validate
@@ -167,12 +167,14 @@ type SearchTasksRequest struct {
 	IsDone      *bool     `json:"is_done"`
 	From        int64     `json:"from"`
 	Size        int64     `json:"size"`
+	Cursor      string    `json:"cursor"`
 }
 
 //SearchTasksResponse defines the response returned back after searching for any task
 type SearchTasksResponse struct {
-	Tasks []Task `json:"tasks"`
-	Total int64  `json:"total"`
+	Tasks      []Task `json:"tasks"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func (t *TaskHandler) search(w http.ResponseWriter, r *http.Request) {
@@ -184,6 +186,12 @@ func (t *TaskHandler) search(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
+	if req.From < 0 || req.Size < 0 {
+		renderErrorResponse(w, r, "invalid request",
+			internal.NewErrorf(internal.ErrorCodeInvalidArgument, "from and size must not be negative"))
+		return
+	}
+
 	var priority *internal.Priority
 	if req.Priority != nil {
 		res := req.Priority.Convert()
@@ -196,6 +204,7 @@ func (t *TaskHandler) search(w http.ResponseWriter, r *http.Request) {
 		IsDone:      req.IsDone,
 		From:        req.From,
 		Size:        req.Size,
+		Cursor:      req.Cursor,
 	})
 	if err != nil {
 		renderErrorResponse(w, r, "search failed", err)
@@ -208,9 +217,10 @@ func (t *TaskHandler) search(w http.ResponseWriter, r *http.Request) {
 		tasks[i].Description = task.Description
 		tasks[i].Priority = NewPriority(task.Priority)
 		tasks[i].Dates = NewDates(task.Dates)
+		tasks[i].IsDone = task.IsDone
 	}
 	renderResponse(w, r,
-		&SearchTasksResponse{Tasks: tasks, Total: res.Total},
+		&SearchTasksResponse{Tasks: tasks, Total: res.Total, NextCursor: res.NextCursor},
 		http.StatusOK)
 
 }
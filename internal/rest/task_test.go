@@ -0,0 +1,51 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sanLimbu/todo-api/internal/rest"
+	"github.com/sanLimbu/todo-api/internal/rest/resttesting"
+)
+
+func TestTaskHandler_Search(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"negative from", `{"description":"milk","from":-1,"size":10}`},
+		{"negative size", `{"description":"milk","size":-1}`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &resttesting.FakeTaskService{}
+
+			router := chi.NewRouter()
+			rest.NewTaskHandler(svc).Register(router)
+
+			req := httptest.NewRequest(http.MethodPost, "/search/tasks", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+
+			if svc.ByCallCount() != 0 {
+				t.Fatalf("expected the service not to be called")
+			}
+		})
+	}
+}
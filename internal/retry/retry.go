@@ -0,0 +1,73 @@
+// Package retry defines the policy used for retrying failed operations, for example indexing events consumed from
+// message brokers.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+// Policy defines how many times and how often an operation is retried, the interval between attempts grows
+// exponentially and includes jitter.
+type Policy struct {
+	MaxAttempts     int
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+}
+
+// NewPolicy returns the default Policy: 5 attempts starting with a 100ms interval and up to 5s.
+func NewPolicy() Policy {
+	return Policy{
+		MaxAttempts:     5,
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     5 * time.Second,
+		Multiplier:      2,
+	}
+}
+
+// Do calls fn until it succeeds, the attempts are exhausted or ctx is done. Errors with the code
+// internal.ErrorCodeInvalidArgument are not retried because they will never succeed. The last error is returned.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	interval := p.InitialInterval
+
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+
+		if !Retryable(err) || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		// Jitter doesn't require a cryptographically secure generator.
+		wait := interval/2 + time.Duration(rand.Int63n(int64(interval/2)+1))
+
+		select {
+		case <-ctx.Done():
+			return internal.WrapErrorf(ctx.Err(), internal.ErrorCodeUnkown, "context.Done: %s", err)
+		case <-time.After(wait):
+		}
+
+		interval = time.Duration(float64(interval) * p.Multiplier)
+		if interval > p.MaxInterval {
+			interval = p.MaxInterval
+		}
+	}
+}
+
+// Retryable indicates whether retrying the operation that failed with err may succeed.
+func Retryable(err error) bool {
+	var ierr *internal.Error
+	if errors.As(err, &ierr) && ierr.Code() == internal.ErrorCodeInvalidArgument {
+		return false
+	}
+
+	return true
+}
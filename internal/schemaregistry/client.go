@@ -0,0 +1,85 @@
+// Package schemaregistry implements a client of the Confluent Schema Registry REST API, other registries
+// implementing the same API, like Redpanda and Apicurio, are supported as well.
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// SchemaTypeProtobuf indicates the schema is a Protobuf definition.
+const SchemaTypeProtobuf = "PROTOBUF"
+
+// Client represents the schema registry.
+type Client struct {
+	url    string
+	client *http.Client
+}
+
+// NewClient instantiates the Client, baseURL is the address of the registry, for example http://localhost:8081.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		url: strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Register registers the schema under subject and returns its ID, registering a schema that already exists returns
+// the existing ID.
+func (c *Client) Register(ctx context.Context, subject, schemaType, schema string) (int, error) {
+	body, err := json.Marshal(struct {
+		SchemaType string `json:"schemaType"`
+		Schema     string `json:"schema"`
+	}{
+		SchemaType: schemaType,
+		Schema:     schema,
+	})
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Marshal")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/subjects/%s/versions", c.url, url.PathEscape(subject)),
+		bytes.NewReader(body))
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "http.NewRequestWithContext")
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", contentType)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "client.Do")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return 0, internal.NewErrorf(internal.ErrorCodeUnkown, "registering failed: %s %s", resp.Status, b)
+	}
+
+	var res struct {
+		ID int `json:"id"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "json.Decode")
+	}
+
+	return res.ID, nil
+}
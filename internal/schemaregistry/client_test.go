@@ -0,0 +1,209 @@
+package schemaregistry_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+	"github.com/sanLimbu/todo-api/internal/event/eventpb"
+	"github.com/sanLimbu/todo-api/internal/schemaregistry"
+)
+
+// registry is a stub of the schema registry, IDs are shared across subjects and registering a known schema returns
+// its existing ID like the real registry does.
+type registry struct {
+	mu  sync.Mutex
+	ids map[string]int // subject + schema
+}
+
+func newRegistry(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	reg := &registry{ids: map[string]int{}}
+
+	srv := httptest.NewServer(reg)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	subject, ok := strings.CutPrefix(req.URL.Path, "/subjects/")
+	if !ok || req.Method != http.MethodPost || !strings.HasSuffix(subject, "/versions") {
+		http.NotFound(w, req)
+
+		return
+	}
+
+	subject = strings.TrimSuffix(subject, "/versions")
+
+	if req.Header.Get("Content-Type") != "application/vnd.schemaregistry.v1+json" {
+		http.Error(w, `{"error_code":415,"message":"unsupported media type"}`, http.StatusUnsupportedMediaType)
+
+		return
+	}
+
+	var body struct {
+		SchemaType string `json:"schemaType"`
+		Schema     string `json:"schema"`
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Schema == "" {
+		http.Error(w, `{"error_code":42201,"message":"invalid schema"}`, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if body.SchemaType != schemaregistry.SchemaTypeProtobuf {
+		http.Error(w, `{"error_code":42201,"message":"unsupported schema type"}`, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := subject + "\x00" + body.Schema
+
+	id, ok := r.ids[key]
+	if !ok {
+		id = len(r.ids) + 1
+		r.ids[key] = id
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	_ = json.NewEncoder(w).Encode(map[string]int{"id": id})
+}
+
+func TestClient_Register(t *testing.T) {
+	t.Parallel()
+
+	srv := newRegistry(t)
+	client := schemaregistry.NewClient(srv.URL + "/")
+	ctx := context.Background()
+
+	id, err := client.Register(ctx, "tasks-value", schemaregistry.SchemaTypeProtobuf, eventpb.Schema)
+	if err != nil {
+		t.Fatalf("Register: %s", err)
+	}
+
+	if id != 1 {
+		t.Fatalf("expected ID 1, got %d", id)
+	}
+
+	// Registering the same schema again looks up the existing ID.
+	again, err := client.Register(ctx, "tasks-value", schemaregistry.SchemaTypeProtobuf, eventpb.Schema)
+	if err != nil {
+		t.Fatalf("Register: %s", err)
+	}
+
+	if again != id {
+		t.Fatalf("expected existing ID %d, got %d", id, again)
+	}
+
+	other, err := client.Register(ctx, "tasks/other-value", schemaregistry.SchemaTypeProtobuf, eventpb.Schema)
+	if err != nil {
+		t.Fatalf("Register: %s", err)
+	}
+
+	if other == id {
+		t.Fatalf("expected a new ID for another subject, got %d", other)
+	}
+}
+
+func TestClient_RegisterFailed(t *testing.T) {
+	t.Parallel()
+
+	srv := newRegistry(t)
+
+	_, err := schemaregistry.NewClient(srv.URL).Register(context.Background(), "tasks-value", "AVRO", eventpb.Schema)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+
+	var ierr *internal.Error
+	if !errors.As(err, &ierr) || ierr.Code() != internal.ErrorCodeUnkown {
+		t.Fatalf("expected unknown error, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "422") || !strings.Contains(err.Error(), "unsupported schema type") {
+		t.Fatalf("expected the response to be included, got %q", err)
+	}
+}
+
+func TestProtobufSchemaRegistry(t *testing.T) {
+	t.Parallel()
+
+	srv := newRegistry(t)
+	client := schemaregistry.NewClient(srv.URL)
+
+	// IDs larger than one byte make sure the ID is encoded in big endian.
+	for i := 0; i < 300; i++ {
+		if _, err := client.Register(context.Background(), "padding", schemaregistry.SchemaTypeProtobuf, strings.Repeat("x", i+1)); err != nil {
+			t.Fatalf("Register: %s", err)
+		}
+	}
+
+	id, err := client.Register(context.Background(), "tasks-value", schemaregistry.SchemaTypeProtobuf, eventpb.Schema)
+	if err != nil {
+		t.Fatalf("Register: %s", err)
+	}
+
+	task := internal.Task{
+		ID:          "task-1",
+		Description: "write tests",
+		Priority:    internal.PriorityHigh,
+		Dates:       internal.Dates{Due: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Version:     3,
+	}
+
+	envelope := event.NewTask(context.Background(), event.TypeTaskUpdated, task)
+
+	b, err := event.NewProtobufSchemaRegistry(id).Marshal(envelope)
+	if err != nil {
+		t.Fatalf("Marshal: %s", err)
+	}
+
+	// Magic byte, schema ID in big endian and the message indexes of the first message.
+	if len(b) < 6 || b[0] != 0 || binary.BigEndian.Uint32(b[1:5]) != uint32(id) || b[5] != 0 {
+		t.Fatalf("unexpected header % x for schema ID %d", b[:min(len(b), 6)], id)
+	}
+
+	// Messages are decoded using the content type regardless of the framing.
+	got, err := event.Unmarshal(event.Message{ContentType: event.ContentTypeProtobuf, Body: b})
+	if err != nil {
+		t.Fatalf("Unmarshal: %s", err)
+	}
+
+	if got.ID != envelope.ID || got.Type != envelope.Type || !got.Time.Equal(envelope.Time) {
+		t.Fatalf("unexpected envelope %+v", got)
+	}
+
+	gotTask, err := got.Task()
+	if err != nil {
+		t.Fatalf("Task: %s", err)
+	}
+
+	if gotTask.ID != task.ID || gotTask.Description != task.Description || gotTask.Priority != task.Priority ||
+		!gotTask.Dates.Due.Equal(task.Dates.Due) || gotTask.Version != task.Version {
+		t.Fatalf("unexpected task %+v", gotTask)
+	}
+
+	// Unknown message indexes are rejected instead of being decoded as an envelope.
+	invalid := append([]byte{}, b[:5]...)
+	invalid = append(invalid, 2, 2) // one index, message 1
+	invalid = append(invalid, b[6:]...)
+
+	if _, err := event.Unmarshal(event.Message{ContentType: event.ContentTypeProtobuf, Body: invalid}); err == nil {
+		t.Fatalf("expected error for unknown message index")
+	}
+}
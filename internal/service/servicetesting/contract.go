@@ -0,0 +1,418 @@
+// Package servicetesting defines the contract test suites that every implementation of the repositories required
+// by the service package must pass, adapters call them from their own tests using a live datastore.
+package servicetesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanLimbu/todo-api/internal"
+	"github.com/sanLimbu/todo-api/internal/event"
+	"github.com/sanLimbu/todo-api/internal/service"
+)
+
+// SearchFixture defines the values required for running the TaskSearchRepository contract.
+type SearchFixture struct {
+	Search service.TaskSearchRepository
+
+	// Store persists the task making it searchable, the returned task includes the assigned ID.
+	Store func(ctx context.Context, task internal.Task) (internal.Task, error)
+
+	// Remove deletes the task, it must not be searchable afterwards.
+	Remove func(ctx context.Context, id string) error
+}
+
+// BrokerFixture defines the values required for running the TaskMessageBrokerRepository contract.
+type BrokerFixture struct {
+	Broker service.TaskMessageBrokerRepository
+
+	// Next blocks until the next published event is received, the event type is one of the types defined in the
+	// event package.
+	Next func(ctx context.Context) (eventType string, task internal.Task, err error)
+}
+
+// TaskRepository runs the contract every service.TaskRepository implementation must pass.
+func TaskRepository(t *testing.T, newRepo func(t *testing.T) service.TaskRepository) {
+	t.Helper()
+
+	create := func(t *testing.T, repo service.TaskRepository) internal.Task {
+		t.Helper()
+
+		task, err := repo.Create(context.Background(), internal.CreateParams{
+			Description: "contract task",
+			Priority:    internal.PriorityHigh,
+			Dates: internal.Dates{
+				Start: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+				Due:   time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+			},
+		})
+		if err != nil {
+			t.Fatalf("Create failed: %s", err)
+		}
+
+		return task
+	}
+
+	t.Run("Create and Find", func(t *testing.T) {
+		repo := newRepo(t)
+		created := create(t, repo)
+
+		if _, err := uuid.Parse(created.ID); err != nil {
+			t.Fatalf("expected ID to be an UUID, got %q", created.ID)
+		}
+
+		if created.Version < 1 {
+			t.Fatalf("expected a positive version, got %d", created.Version)
+		}
+
+		found, err := repo.Find(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("Find failed: %s", err)
+		}
+
+		assertTask(t, created, found)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+		created := create(t, repo)
+
+		dates := internal.Dates{Due: time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC)}
+
+		updated, err := repo.Update(context.Background(), created.ID, "updated", internal.PriorityLow, dates, true)
+		if err != nil {
+			t.Fatalf("Update failed: %s", err)
+		}
+
+		found, err := repo.Find(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("Find failed: %s", err)
+		}
+
+		// The updated task is returned, so events can be published without reading it again.
+		assertTask(t, found, updated)
+
+		assertTask(t, internal.Task{
+			ID:          created.ID,
+			Description: "updated",
+			Priority:    internal.PriorityLow,
+			Dates:       dates,
+			IsDone:      true,
+			Version:     found.Version,
+		}, found)
+
+		if found.Version <= created.Version {
+			t.Fatalf("expected version to increase from %d, got %d", created.Version, found.Version)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		created := create(t, repo)
+
+		version, err := repo.Delete(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("Delete failed: %s", err)
+		}
+
+		if version <= created.Version {
+			t.Fatalf("expected the deletion version to be greater than %d, got %d", created.Version, version)
+		}
+
+		_, err = repo.Find(context.Background(), created.ID)
+		assertErrorCode(t, err, internal.ErrorCodeNotFound)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		repo := newRepo(t)
+		id := uuid.NewString()
+
+		_, err := repo.Find(context.Background(), id)
+		assertErrorCode(t, err, internal.ErrorCodeNotFound)
+
+		_, err = repo.Update(context.Background(), id, "updated", internal.PriorityLow, internal.Dates{}, false)
+		assertErrorCode(t, err, internal.ErrorCodeNotFound)
+
+		_, err = repo.Delete(context.Background(), id)
+		assertErrorCode(t, err, internal.ErrorCodeNotFound)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.Find(context.Background(), "invalid")
+		assertErrorCode(t, err, internal.ErrorCodeInvalidArgument)
+	})
+}
+
+// TaskSearchRepository runs the contract every service.TaskSearchRepository implementation must pass.
+func TaskSearchRepository(t *testing.T, newFixture func(t *testing.T) SearchFixture) {
+	t.Helper()
+
+	type seeded struct {
+		fixture SearchFixture
+		milk    internal.Task
+		bread   internal.Task
+		eggs    internal.Task
+	}
+
+	seed := func(t *testing.T) seeded {
+		t.Helper()
+
+		fixture := newFixture(t)
+
+		store := func(task internal.Task) internal.Task {
+			res, err := fixture.Store(context.Background(), task)
+			if err != nil {
+				t.Fatalf("Store failed: %s", err)
+			}
+
+			return res
+		}
+
+		return seeded{
+			fixture: fixture,
+			milk:    store(internal.Task{Description: "buy milk", Priority: internal.PriorityHigh}),
+			bread:   store(internal.Task{Description: "bake bread", Priority: internal.PriorityLow, IsDone: true}),
+			eggs:    store(internal.Task{Description: "collect eggs", Priority: internal.PriorityLow}),
+		}
+	}
+
+	search := func(t *testing.T, repo service.TaskSearchRepository, args internal.SearchParams) internal.SearchResults {
+		t.Helper()
+
+		res, err := repo.Search(context.Background(), args)
+		if err != nil {
+			t.Fatalf("Search failed: %s", err)
+		}
+
+		return res
+	}
+
+	newPriority := func(p internal.Priority) *internal.Priority { return &p }
+	newString := func(s string) *string { return &s }
+	newBool := func(b bool) *bool { return &b }
+
+	t.Run("No arguments", func(t *testing.T) {
+		s := seed(t)
+
+		res := search(t, s.fixture.Search, internal.SearchParams{Size: 10})
+		assertIDs(t, res.Task)
+	})
+
+	t.Run("Description", func(t *testing.T) {
+		s := seed(t)
+
+		res := search(t, s.fixture.Search, internal.SearchParams{Description: newString("milk"), Size: 10})
+		assertIDs(t, res.Task, s.milk.ID)
+
+		if res.Task[0].Priority != internal.PriorityHigh {
+			t.Fatalf("expected priority %d, got %d", internal.PriorityHigh, res.Task[0].Priority)
+		}
+	})
+
+	t.Run("Priority", func(t *testing.T) {
+		s := seed(t)
+
+		res := search(t, s.fixture.Search, internal.SearchParams{Priority: newPriority(internal.PriorityLow), Size: 10})
+		assertIDs(t, res.Task, s.bread.ID, s.eggs.ID)
+
+		if res.Total != 2 {
+			t.Fatalf("expected total 2, got %d", res.Total)
+		}
+	})
+
+	t.Run("IsDone", func(t *testing.T) {
+		s := seed(t)
+
+		res := search(t, s.fixture.Search, internal.SearchParams{IsDone: newBool(true), Size: 10})
+		assertIDs(t, res.Task, s.bread.ID)
+
+		if !res.Task[0].IsDone {
+			t.Fatalf("expected task to be done")
+		}
+	})
+
+	t.Run("Any value matches", func(t *testing.T) {
+		s := seed(t)
+
+		res := search(t, s.fixture.Search, internal.SearchParams{
+			Description: newString("milk"),
+			IsDone:      newBool(true),
+			Size:        10,
+		})
+		assertIDs(t, res.Task, s.milk.ID, s.bread.ID)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		s := seed(t)
+
+		args := internal.SearchParams{Priority: newPriority(internal.PriorityLow), Size: 1}
+
+		first := search(t, s.fixture.Search, args)
+		if len(first.Task) != 1 || first.NextCursor == "" {
+			t.Fatalf("expected one task and a cursor, got %d tasks and %q", len(first.Task), first.NextCursor)
+		}
+
+		second := search(t, s.fixture.Search, internal.SearchParams{Priority: args.Priority, Size: 1, From: 1})
+		assertIDs(t, append(first.Task, second.Task...), s.bread.ID, s.eggs.ID)
+
+		tasks := first.Task
+		args.Cursor = first.NextCursor
+
+		for i := 0; args.Cursor != ""; i++ {
+			if i > 3 {
+				t.Fatalf("expected cursor pagination to end")
+			}
+
+			res := search(t, s.fixture.Search, args)
+			tasks = append(tasks, res.Task...)
+			args.Cursor = res.NextCursor
+		}
+
+		assertIDs(t, tasks, s.bread.ID, s.eggs.ID)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		s := seed(t)
+
+		_, err := s.fixture.Search.Search(context.Background(), internal.SearchParams{
+			Priority: newPriority(internal.PriorityLow),
+			Size:     1,
+			Cursor:   "!",
+		})
+		assertErrorCode(t, err, internal.ErrorCodeInvalidArgument)
+	})
+
+	t.Run("Cursor of another search", func(t *testing.T) {
+		s := seed(t)
+
+		first := search(t, s.fixture.Search, internal.SearchParams{Priority: newPriority(internal.PriorityLow), Size: 1})
+		if first.NextCursor == "" {
+			t.Fatalf("expected a cursor")
+		}
+
+		_, err := s.fixture.Search.Search(context.Background(), internal.SearchParams{
+			Priority: newPriority(internal.PriorityHigh),
+			Size:     1,
+			Cursor:   first.NextCursor,
+		})
+		assertErrorCode(t, err, internal.ErrorCodeInvalidArgument)
+	})
+
+	t.Run("Removed", func(t *testing.T) {
+		s := seed(t)
+
+		if err := s.fixture.Remove(context.Background(), s.milk.ID); err != nil {
+			t.Fatalf("Remove failed: %s", err)
+		}
+
+		res := search(t, s.fixture.Search, internal.SearchParams{Description: newString("milk"), Size: 10})
+		assertIDs(t, res.Task)
+	})
+}
+
+// TaskMessageBrokerRepository runs the contract every service.TaskMessageBrokerRepository implementation must pass.
+func TaskMessageBrokerRepository(t *testing.T, newFixture func(t *testing.T) BrokerFixture) {
+	t.Helper()
+
+	fixture := newFixture(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task := internal.Task{
+		ID:          uuid.NewString(),
+		Description: "published",
+		Priority:    internal.PriorityMedium,
+		Version:     1,
+	}
+
+	next := func(expectedType string, expected internal.Task) {
+		t.Helper()
+
+		eventType, received, err := fixture.Next(ctx)
+		if err != nil {
+			t.Fatalf("Next failed: %s", err)
+		}
+
+		if eventType != expectedType {
+			t.Fatalf("expected event %q, got %q", expectedType, eventType)
+		}
+
+		if received.ID != expected.ID || received.Description != expected.Description || received.Version != expected.Version {
+			t.Fatalf("expected task %+v, got %+v", expected, received)
+		}
+	}
+
+	if err := fixture.Broker.Created(ctx, task); err != nil {
+		t.Fatalf("Created failed: %s", err)
+	}
+
+	next(event.TypeTaskCreated, task)
+
+	task.IsDone = true
+	task.Version++
+
+	if err := fixture.Broker.Updated(ctx, task); err != nil {
+		t.Fatalf("Updated failed: %s", err)
+	}
+
+	next(event.TypeTaskUpdated, task)
+
+	task.Version++
+
+	if err := fixture.Broker.Deleted(ctx, task.ID, task.Version); err != nil {
+		t.Fatalf("Deleted failed: %s", err)
+	}
+
+	next(event.TypeTaskDeleted, internal.Task{ID: task.ID, Version: task.Version})
+}
+
+func assertTask(t *testing.T, expected, actual internal.Task) {
+	t.Helper()
+
+	if expected.ID != actual.ID ||
+		expected.Description != actual.Description ||
+		expected.Priority != actual.Priority ||
+		expected.IsDone != actual.IsDone ||
+		expected.Version != actual.Version ||
+		!expected.Dates.Start.Equal(actual.Dates.Start) ||
+		!expected.Dates.Due.Equal(actual.Dates.Due) {
+		t.Fatalf("expected task %+v, got %+v", expected, actual)
+	}
+}
+
+func assertIDs(t *testing.T, tasks []internal.Task, ids ...string) {
+	t.Helper()
+
+	expected := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		expected[id] = struct{}{}
+	}
+
+	if len(tasks) != len(ids) {
+		t.Fatalf("expected %d tasks, got %d", len(ids), len(tasks))
+	}
+
+	for _, task := range tasks {
+		if _, ok := expected[task.ID]; !ok {
+			t.Fatalf("unexpected task %q", task.ID)
+		}
+
+		delete(expected, task.ID)
+	}
+}
+
+func assertErrorCode(t *testing.T, err error, code internal.ErrorCode) {
+	t.Helper()
+
+	var ierr *internal.Error
+	if !errors.As(err, &ierr) || ierr.Code() != code {
+		t.Fatalf("expected error code %d, got %v", code, err)
+	}
+}
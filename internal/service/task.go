@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
@@ -18,9 +21,11 @@ const otelName = "github.com/sanLimbu/todo-api/internal/service"
 
 type TaskRepository interface {
 	Create(ctx context.Context, args internal.CreateParams) (internal.Task, error)
-	Delete(ctx context.Context, id string) error
+	// Delete returns the version of the deletion, it is newer than any version of the task.
+	Delete(ctx context.Context, id string) (int64, error)
 	Find(ctx context.Context, id string) (internal.Task, error)
-	Update(ctx context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) error
+	// Update returns the updated task, including its new version.
+	Update(ctx context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) (internal.Task, error)
 }
 
 //TaskSearchRepository defines the datastore handling searching Task records
@@ -31,24 +36,35 @@ type TaskSearchRepository interface {
 //TaskMessageBrokerRepository defines the datasource handling persisting Searchable Task Records
 type TaskMessageBrokerRepository interface {
 	Created(ctx context.Context, task internal.Task) error
-	Deleted(ctx context.Context, id string) error
+	Deleted(ctx context.Context, id string, version int64) error
 	Updated(ctx context.Context, task internal.Task) error
 }
 
 //Task defines the application service in charge of interacting with Tasks
 type Task struct {
-	repo      TaskRepository
-	search    TaskSearchRepository
-	msgBroker TaskMessageBrokerRepository
-	cb        *circuitbreaker.CircuitBreaker
+	repo            TaskRepository
+	search          TaskSearchRepository
+	msgBroker       TaskMessageBrokerRepository
+	cb              *circuitbreaker.CircuitBreaker
+	operations      metric.Int64Counter
+	publishFailures metric.Int64Counter
 }
 
 //NewTask
 func NewTask(logger *zap.Logger, repo TaskRepository, search TaskSearchRepository, msgBroker TaskMessageBrokerRepository) *Task {
-	return &Task{
-		repo:      repo,
-		search:    search,
-		msgBroker: msgBroker,
+	meter := otel.Meter(otelName)
+
+	operations, _ := meter.Int64Counter("service.task.operations",
+		metric.WithDescription("Number of Task operations by outcome"))
+	publishFailures, _ := meter.Int64Counter("service.task.publish_failures",
+		metric.WithDescription("Number of Task events that couldn't be published"))
+
+	t := &Task{
+		repo:            repo,
+		search:          search,
+		msgBroker:       msgBroker,
+		operations:      operations,
+		publishFailures: publishFailures,
 		cb: circuitbreaker.New(
 			circuitbreaker.WithOpenTimeout(time.Minute*2),
 			circuitbreaker.WithTripFunc(circuitbreaker.NewTripFuncConsecutiveFailures(3)),
@@ -60,12 +76,33 @@ func NewTask(logger *zap.Logger, repo TaskRepository, search TaskSearchRepositor
 			}),
 		),
 	}
+
+	// The current state is 1, the others are 0.
+	_, _ = meter.Int64ObservableGauge("service.circuit_breaker.state",
+		metric.WithDescription("State of the circuit breaker protecting searches"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			current := t.cb.State()
+
+			for _, state := range []circuitbreaker.State{circuitbreaker.StateClosed, circuitbreaker.StateOpen, circuitbreaker.StateHalfOpen} {
+				var val int64
+				if state == current {
+					val = 1
+				}
+
+				o.Observe(val, metric.WithAttributes(attribute.String("state", string(state))))
+			}
+
+			return nil
+		}))
+
+	return t
 }
 
 // By searches Tasks matching the received values.
 func (t *Task) By(ctx context.Context, args internal.SearchParams) (_ internal.SearchResults, err error) {
 
 	defer newOTELSpan(ctx, "Task.By").End()
+	defer t.record(ctx, "By", &err)
 
 	if !t.cb.Ready() {
 		return internal.SearchResults{}, internal.NewErrorf(internal.ErrorCodeUnkown, "service not available")
@@ -85,9 +122,10 @@ func (t *Task) By(ctx context.Context, args internal.SearchParams) (_ internal.S
 }
 
 //Create stores a new record
-func (t *Task) Create(ctx context.Context, params internal.CreateParams) (internal.Task, error) {
+func (t *Task) Create(ctx context.Context, params internal.CreateParams) (_ internal.Task, err error) {
 
 	defer newOTELSpan(ctx, "Task.Create").End()
+	defer t.record(ctx, "Create", &err)
 
 	if err := params.Validate(); err != nil {
 		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "params.Validate")
@@ -97,26 +135,29 @@ func (t *Task) Create(ctx context.Context, params internal.CreateParams) (intern
 	if err != nil {
 		return internal.Task{}, internal.WrapErrorf(err, internal.ErrorCodeUnkown, "repo.Created")
 	}
-	_ = t.msgBroker.Created(ctx, task)
+	t.publish(ctx, "created", t.msgBroker.Created(ctx, task))
 	return task, nil
 }
 
 //Delete removes an existing Task from the datastore
-func (t *Task) Delete(ctx context.Context, id string) error {
+func (t *Task) Delete(ctx context.Context, id string) (err error) {
 
 	defer newOTELSpan(ctx, "Task.Delete").End()
+	defer t.record(ctx, "Delete", &err)
 
-	if err := t.repo.Delete(ctx, id); err != nil {
+	version, err := t.repo.Delete(ctx, id)
+	if err != nil {
 		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "Delete")
 	}
-	_ = t.msgBroker.Deleted(ctx, id)
+	t.publish(ctx, "deleted", t.msgBroker.Deleted(ctx, id, version))
 	return nil
 }
 
 // Task gets an existing Task from the datastore.
-func (t *Task) Task(ctx context.Context, id string) (internal.Task, error) {
+func (t *Task) Task(ctx context.Context, id string) (_ internal.Task, err error) {
 
 	defer newOTELSpan(ctx, "Task.Task").End()
+	defer t.record(ctx, "Task", &err)
 
 	task, err := t.repo.Find(ctx, id)
 	if err != nil {
@@ -127,21 +168,54 @@ func (t *Task) Task(ctx context.Context, id string) (internal.Task, error) {
 }
 
 // Update updates an existing Task in the datastore.
-func (t *Task) Update(ctx context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) error {
+func (t *Task) Update(ctx context.Context, id string, description string, priority internal.Priority, dates internal.Dates, isDone bool) (err error) {
 
 	defer newOTELSpan(ctx, "Task.Update").End()
+	defer t.record(ctx, "Update", &err)
 
-	if err := t.repo.Update(ctx, id, description, priority, dates, isDone); err != nil {
+	task, err := t.repo.Update(ctx, id, description, priority, dates, isDone)
+	if err != nil {
 		return internal.WrapErrorf(err, internal.ErrorCodeUnkown, "repo.update")
 	}
-	{
-		task, err := t.repo.Find(ctx, id)
-		if err == nil {
-			_ = t.msgBroker.Updated(ctx, task) // XXX: Ignoring errors on purpose
+
+	t.publish(ctx, "updated", t.msgBroker.Updated(ctx, task))
+
+	return nil
+}
+
+// record counts the operation using the error it returned, err is read when the deferred call runs.
+func (t *Task) record(ctx context.Context, operation string, err *error) {
+	t.operations.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("operation", operation),
+		attribute.String("outcome", outcome(*err))))
+}
+
+// publish counts the events that couldn't be published, they don't fail the operation.
+func (t *Task) publish(ctx context.Context, eventType string, err error) {
+	if err != nil {
+		t.publishFailures.Add(ctx, 1, metric.WithAttributes(attribute.String("type", eventType)))
+	}
+}
+
+// outcome returns "success" when err is nil, otherwise the first not found or invalid argument code in the chain, if
+// any, or "failure".
+func outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+
+	var ierr *internal.Error
+
+	for e := err; errors.As(e, &ierr); e = ierr.Unwrap() {
+		switch ierr.Code() {
+		case internal.ErrorCodeNotFound:
+			return "not_found"
+		case internal.ErrorCodeInvalidArgument:
+			return "invalid_argument"
 		}
 	}
 
-	return nil
+	return "failure"
 }
 
 func newOTELSpan(ctx context.Context, name string) trace.Span {
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"fmt"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
@@ -32,6 +33,31 @@ func (p Priority) Validate() error {
 	return NewErrorf(ErrorCodeInvalidArgument, "unkown value")
 }
 
+//String returns the name of the priority used by the API and events: "none", "low", "medium" or "high".
+func (p Priority) String() string {
+	switch p {
+	case PriorityNone:
+		return "none"
+	case PriorityLow:
+		return "low"
+	case PriorityMedium:
+		return "medium"
+	case PriorityHigh:
+		return "high"
+	}
+	return fmt.Sprintf("Priority(%d)", p)
+}
+
+//ParsePriority returns the priority named s, names are the ones returned by Priority.String.
+func ParsePriority(s string) (Priority, error) {
+	for _, p := range []Priority{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh} {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return Priority(-1), NewErrorf(ErrorCodeInvalidArgument, "unknown value: %s", s)
+}
+
 //Category is human readable value meant to be used to origanize your tasks. Category values are unique
 type Category string
 
@@ -58,6 +84,9 @@ type Task struct {
 	SubTasks    []Task
 	Categories  []Category
 	IsDone      bool
+
+	// Version increases every time the task changes, consumers of events use it for ignoring stale events.
+	Version int64
 }
 
 // Validate ...
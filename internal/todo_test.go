@@ -0,0 +1,28 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sanLimbu/todo-api/internal"
+)
+
+func TestParsePriority(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []internal.Priority{internal.PriorityNone, internal.PriorityLow, internal.PriorityMedium, internal.PriorityHigh} {
+		got, err := internal.ParsePriority(p.String())
+		if err != nil {
+			t.Fatalf("ParsePriority(%q): %s", p, err)
+		}
+
+		if got != p {
+			t.Fatalf("expected %s, got %s", p, got)
+		}
+	}
+
+	for _, s := range []string{"", "urgent", internal.Priority(9).String()} {
+		if _, err := internal.ParsePriority(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
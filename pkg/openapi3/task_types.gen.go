@@ -52,8 +52,9 @@ type ReadTasksResponse struct {
 
 // SearchTasksResponse defines model for SearchTasksResponse.
 type SearchTasksResponse struct {
-	Tasks *[]Task `json:"tasks,omitempty"`
-	Total *int64  `json:"total,omitempty"`
+	NextCursor *string `json:"next_cursor,omitempty"`
+	Tasks      *[]Task `json:"tasks,omitempty"`
+	Total      *int64  `json:"total,omitempty"`
 }
 
 // CreateTasksRequest defines model for CreateTasksRequest.
@@ -65,6 +66,7 @@ type CreateTasksRequest struct {
 
 // SearchTasksRequest defines model for SearchTasksRequest.
 type SearchTasksRequest struct {
+	Cursor      *string   `json:"cursor,omitempty"`
 	Description *string   `json:"description"`
 	From        *int64    `json:"from,omitempty"`
 	IsDone      *bool     `json:"is_done"`
@@ -82,6 +84,7 @@ type UpdateTasksRequest struct {
 
 // SearchTaskJSONBody defines parameters for SearchTask.
 type SearchTaskJSONBody struct {
+	Cursor      *string   `json:"cursor,omitempty"`
 	Description *string   `json:"description"`
 	From        *int64    `json:"from,omitempty"`
 	IsDone      *bool     `json:"is_done"`